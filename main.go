@@ -24,12 +24,16 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"math/rand"
 	"net/http"
 	pprofhttp "net/http/pprof"
+	"net/url"
 	"os"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
@@ -42,7 +46,14 @@ import (
 	"github.com/Arriven/db1000n/src/utils/ota"
 )
 
-const simpleLogFormat = "simple"
+const (
+	simpleLogFormat = "simple"
+	jsonLogFormat   = "json"
+
+	progressSinkScheme = "progress"
+)
+
+var registerProgressSink sync.Once
 
 func main() {
 	runnerConfigOptions := job.NewConfigOptionsWithFlags()
@@ -59,14 +70,15 @@ func main() {
 	logFormat := flag.String("log-format", utils.GetEnvStringDefault("LOG_FORMAT", simpleLogFormat), "overrides the default (simple) log output format,\n"+
 		"possible values are: json, console, simple\n"+
 		"simple is the most human readable format if you only look at the output in your terminal")
+	traceRender := flag.String("trace-render", "", "convert the job trace file at the given path to chrome trace format (chrome://tracing), print it and exit")
 	lessStats := flag.Bool("less-stats", utils.GetEnvBoolDefault("LESS_STATS", false), "group target stats by protocols - in case you have too many targets")
+	runSingleJob := flag.Bool(job.RunSingleJobFlag, false, "read a single job config from stdin, run it and write its stats to stdout (used by the subprocess job)")
+	deltaStats := flag.Bool("delta-stats", utils.GetEnvBoolDefault("DELTA_STATS", false), "report only stats increments since the previous report instead of totals")
 
 	flag.Parse()
 
-	logger, err := newZapLogger(*debug, *logLevel, *logFormat)
-	if err != nil {
-		panic(err)
-	}
+	logger := utils.Must(newZapLogger(*debug, *logLevel, *logFormat,
+		zap.String("client_id", jobsGlobalConfig.ClientID), zap.String("version", ota.Version), zap.String("goos", runtime.GOOS)))
 
 	logger.Info("running db1000n", zap.String("version", ota.Version), zap.Int("pid", os.Getpid()))
 
@@ -76,6 +88,24 @@ func main() {
 
 		return
 	case *version:
+		return
+	case jobsGlobalConfig.ExportMetricsSchema:
+		if err := json.NewEncoder(os.Stdout).Encode(metrics.Schema()); err != nil {
+			logger.Fatal("failed to export metrics schema", zap.Error(err))
+		}
+
+		return
+	case *traceRender != "":
+		if err := renderTrace(*traceRender); err != nil {
+			logger.Fatal("failed to render trace", zap.Error(err))
+		}
+
+		return
+	case *runSingleJob:
+		if err := job.RunSingleJob(context.Background(), os.Stdin, os.Stdout, logger); err != nil {
+			logger.Fatal("failed to run job", zap.Error(err))
+		}
+
 		return
 	case *updaterMode:
 		config.UpdateLocal(logger, *destinationPath, strings.Split(runnerConfigOptions.PathsCSV, ","), []byte(runnerConfigOptions.BackupConfig),
@@ -84,13 +114,26 @@ func main() {
 		return
 	}
 
-	err = utils.UpdateRLimit()
-	if err != nil {
+	if err := utils.UpdateRLimit(); err != nil {
 		logger.Warn("failed to increase rlimit", zap.Error(err))
 	}
 
+	if procs, err := utils.UpdateGOMAXPROCS(); err != nil {
+		logger.Debug("failed to read cgroup cpu quota", zap.Error(err))
+	} else {
+		logger.Debug("GOMAXPROCS", zap.Int("value", procs))
+	}
+
+	if limit, err := utils.UpdateMemoryLimit(); err != nil {
+		logger.Debug("failed to set memory limit", zap.Error(err))
+	} else if limit > 0 {
+		logger.Debug("memory limit", zap.Int64("bytes", limit))
+	}
+
+	runner := job.NewRunner(runnerConfigOptions, jobsGlobalConfig, newReporter(*logFormat, *lessStats, *deltaStats, logger))
+
 	go ota.WatchUpdates(logger, otaConfig)
-	setUpPprof(logger, *pprof, *debug)
+	setUpPprof(logger, *pprof, *debug, runner.History(), runner.ActiveJobs())
 	rand.Seed(time.Now().UnixNano())
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -100,17 +143,55 @@ func main() {
 
 	metrics.InitOrFail(ctx, logger, *prometheusOn, *prometheusListenAddress, jobsGlobalConfig.ClientID, country)
 
-	reporter := newReporter(*logFormat, *lessStats, logger)
-	job.NewRunner(runnerConfigOptions, jobsGlobalConfig, reporter).Run(ctx, logger)
+	if err := runner.Run(ctx, logger); err != nil {
+		cancel()
+		logger.Fatal("runner failed", zap.Error(err))
+	}
 }
 
-func newZapLogger(debug bool, logLevel string, logFormat string) (*zap.Logger, error) {
+func renderTrace(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return job.RenderTrace(file, os.Stdout)
+}
+
+// newZapLogger builds a logger for the given format, fields are only attached to json output to make it easier to ingest
+func newZapLogger(debug bool, logLevel string, logFormat string, fields ...zap.Field) (*zap.Logger, error) {
+	cfg, opts := newZapConfig(debug, logFormat, fields...)
+
+	level, err := zap.ParseAtomicLevel(logLevel)
+	if err == nil {
+		cfg.Level = level
+	}
+
+	// logs share stderr with job progress bars, writing them through the bar renderer keeps the two from mixing
+	registerProgressSink.Do(func() {
+		_ = zap.RegisterSink(progressSinkScheme, func(*url.URL) (zap.Sink, error) { return job.ProgressAwareStderr(), nil })
+	})
+
+	for i, path := range cfg.OutputPaths {
+		if path == "stderr" {
+			cfg.OutputPaths[i] = progressSinkScheme + ":stderr"
+		}
+	}
+
+	return cfg.Build(opts...)
+}
+
+func newZapConfig(debug bool, logFormat string, fields ...zap.Field) (zap.Config, []zap.Option) {
 	cfg := zap.NewProductionConfig()
 	if debug {
 		cfg = zap.NewDevelopmentConfig()
 	}
 
-	if logFormat == simpleLogFormat {
+	var opts []zap.Option
+
+	switch logFormat {
+	case simpleLogFormat:
 		// turn off all output except the message itself and log level
 		cfg.Encoding = "console"
 		cfg.EncoderConfig.TimeKey = ""
@@ -121,7 +202,21 @@ func newZapLogger(debug bool, logLevel string, logFormat string) (*zap.Logger, e
 			cfg.EncoderConfig.CallerKey = ""
 			cfg.EncoderConfig.StacktraceKey = ""
 		}
-	} else if logFormat != "" {
+	case jsonLogFormat:
+		// use Elastic Common Schema field names so that output can be ingested by ELK/Loki without extra mapping
+		cfg.Encoding = jsonLogFormat
+		cfg.EncoderConfig.TimeKey = "@timestamp"
+		cfg.EncoderConfig.LevelKey = "log.level"
+		cfg.EncoderConfig.NameKey = "log.logger"
+		cfg.EncoderConfig.CallerKey = "log.origin"
+		cfg.EncoderConfig.MessageKey = "message"
+		cfg.EncoderConfig.StacktraceKey = "error.stack_trace"
+		cfg.EncoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+		cfg.EncoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+		opts = append(opts, zap.Fields(fields...))
+	case "":
+		// keep zap defaults
+	default:
 		cfg.Encoding = logFormat
 
 		if logFormat == "console" {
@@ -129,15 +224,10 @@ func newZapLogger(debug bool, logLevel string, logFormat string) (*zap.Logger, e
 		}
 	}
 
-	level, err := zap.ParseAtomicLevel(logLevel)
-	if err == nil {
-		cfg.Level = level
-	}
-
-	return cfg.Build()
+	return cfg, opts
 }
 
-func setUpPprof(logger *zap.Logger, pprof string, debug bool) {
+func setUpPprof(logger *zap.Logger, pprof string, debug bool, history, activeJobs http.Handler) {
 	switch {
 	case debug && pprof == "":
 		pprof = ":8080"
@@ -151,15 +241,17 @@ func setUpPprof(logger *zap.Logger, pprof string, debug bool) {
 	mux.Handle("/debug/pprof/profile", http.HandlerFunc(pprofhttp.Profile))
 	mux.Handle("/debug/pprof/symbol", http.HandlerFunc(pprofhttp.Symbol))
 	mux.Handle("/debug/pprof/trace", http.HandlerFunc(pprofhttp.Trace))
+	mux.Handle("/api/v1/history", history)
+	mux.Handle("/api/v1/jobs", activeJobs)
 
 	// this has to be wrapped into a lambda bc otherwise it blocks when evaluating argument for zap.Error
 	go func() { logger.Warn("pprof server", zap.Error(http.ListenAndServe(pprof, mux))) }()
 }
 
-func newReporter(logFormat string, groupTargets bool, logger *zap.Logger) metrics.Reporter {
+func newReporter(logFormat string, groupTargets, delta bool, logger *zap.Logger) metrics.Reporter {
 	if logFormat == simpleLogFormat {
-		return metrics.NewConsoleReporter(os.Stdout, groupTargets)
+		return metrics.NewConsoleReporter(os.Stdout, groupTargets, delta)
 	}
 
-	return metrics.NewZapReporter(logger, groupTargets)
+	return metrics.NewZapReporter(logger, groupTargets, delta)
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestJSONLogFormatUsesECSFieldNames(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "log.json")
+
+	cfg, opts := newZapConfig(false, jsonLogFormat, zap.String("client_id", "test-client"))
+	cfg.OutputPaths = []string{path}
+
+	logger, err := cfg.Build(append(opts, zap.AddStacktrace(zap.InfoLevel))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Named("runner").Info("hello")
+	_ = logger.Sync()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(content, &entry); err != nil {
+		t.Fatalf("invalid log entry %q: %v", content, err)
+	}
+
+	expected := map[string]any{"log.level": "info", "log.logger": "runner", "message": "hello", "client_id": "test-client"}
+	for key, value := range expected {
+		if entry[key] != value {
+			t.Errorf("expected %q to be %v, got %v", key, value, entry[key])
+		}
+	}
+
+	for _, key := range []string{"@timestamp", "log.origin", "error.stack_trace"} {
+		if _, ok := entry[key]; !ok {
+			t.Errorf("missing %q in %s", key, content)
+		}
+	}
+}
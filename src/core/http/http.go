@@ -28,6 +28,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net"
+	"net/http"
 	"time"
 
 	"github.com/corpix/uarand"
@@ -94,6 +95,7 @@ func (c *StaticHostClient) Do(req *fasthttp.Request, resp *fasthttp.Response) er
 type ClientConfig struct {
 	StaticHost      *StaticHostConfig
 	TLSClientConfig *tls.Config
+	ClientCert      *utils.ClientCertConfig
 	Timeout         *time.Duration
 	ReadTimeout     *time.Duration
 	WriteTimeout    *time.Duration
@@ -109,10 +111,8 @@ func NewClient(ctx context.Context, clientConfig ClientConfig, logger *zap.Logge
 		defaultTimeout         = 90 * time.Second
 	)
 
-	timeout := utils.NonNilOrDefault(clientConfig.Timeout, defaultTimeout)
-	tlsConfig := utils.NonNilOrDefault(clientConfig.TLSClientConfig, tls.Config{
-		InsecureSkipVerify: true, //nolint:gosec // This is intentional
-	})
+	timeout := utils.Deref(clientConfig.Timeout, defaultTimeout)
+	tlsConfig := newTLSConfig(clientConfig, logger)
 	proxyFunc := utils.GetProxyFunc(*clientConfig.Proxy, "http")
 
 	if clientConfig.StaticHost != nil {
@@ -121,14 +121,14 @@ func NewClient(ctx context.Context, clientConfig ClientConfig, logger *zap.Logge
 				Addr:                          clientConfig.StaticHost.Addr,
 				IsTLS:                         tls,
 				MaxConnDuration:               timeout,
-				ReadTimeout:                   utils.NonNilOrDefault(clientConfig.ReadTimeout, timeout),
-				WriteTimeout:                  utils.NonNilOrDefault(clientConfig.WriteTimeout, timeout),
-				MaxIdleConnDuration:           utils.NonNilOrDefault(clientConfig.IdleTimeout, timeout),
-				MaxConns:                      utils.NonNilOrDefault(clientConfig.MaxIdleConns, defaultMaxConnsPerHost),
+				ReadTimeout:                   utils.Deref(clientConfig.ReadTimeout, timeout),
+				WriteTimeout:                  utils.Deref(clientConfig.WriteTimeout, timeout),
+				MaxIdleConnDuration:           utils.Deref(clientConfig.IdleTimeout, timeout),
+				MaxConns:                      utils.Deref(clientConfig.MaxIdleConns, defaultMaxConnsPerHost),
 				NoDefaultUserAgentHeader:      true, // Don't send: User-Agent: fasthttp
 				DisableHeaderNamesNormalizing: true, // If you set the case on your headers correctly you can enable this
 				DisablePathNormalizing:        true,
-				TLSConfig:                     &tlsConfig,
+				TLSConfig:                     tlsConfig,
 				Dial:                          dialViaProxyFunc(proxyFunc, "tcp"),
 			}
 		}
@@ -145,18 +145,53 @@ func NewClient(ctx context.Context, clientConfig ClientConfig, logger *zap.Logge
 
 	return &fasthttp.Client{
 		MaxConnDuration:               timeout,
-		ReadTimeout:                   utils.NonNilOrDefault(clientConfig.ReadTimeout, timeout),
-		WriteTimeout:                  utils.NonNilOrDefault(clientConfig.WriteTimeout, timeout),
-		MaxIdleConnDuration:           utils.NonNilOrDefault(clientConfig.IdleTimeout, timeout),
-		MaxConnsPerHost:               utils.NonNilOrDefault(clientConfig.MaxIdleConns, defaultMaxConnsPerHost),
+		ReadTimeout:                   utils.Deref(clientConfig.ReadTimeout, timeout),
+		WriteTimeout:                  utils.Deref(clientConfig.WriteTimeout, timeout),
+		MaxIdleConnDuration:           utils.Deref(clientConfig.IdleTimeout, timeout),
+		MaxConnsPerHost:               utils.Deref(clientConfig.MaxIdleConns, defaultMaxConnsPerHost),
 		NoDefaultUserAgentHeader:      true, // Don't send: User-Agent: fasthttp
 		DisableHeaderNamesNormalizing: true, // If you set the case on your headers correctly you can enable this
 		DisablePathNormalizing:        true,
-		TLSConfig:                     &tlsConfig,
+		TLSConfig:                     tlsConfig,
 		Dial:                          dialViaProxyFunc(proxyFunc, "tcp"),
 	}
 }
 
+// NewStdClient creates a net/http client based on the config for libraries that don't support fasthttp.
+// Only timeout, tls and proxy settings apply.
+func NewStdClient(clientConfig ClientConfig, logger *zap.Logger) *http.Client {
+	const defaultTimeout = 90 * time.Second
+
+	tlsConfig := newTLSConfig(clientConfig, logger)
+	proxyFunc := utils.GetProxyFunc(utils.Deref(clientConfig.Proxy, utils.ProxyParams{}), "http")
+
+	return &http.Client{
+		Timeout: utils.Deref(clientConfig.Timeout, defaultTimeout),
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, network, addr string) (net.Conn, error) {
+				return proxyFunc(network, addr)
+			},
+			TLSClientConfig: tlsConfig,
+		},
+	}
+}
+
+func newTLSConfig(clientConfig ClientConfig, logger *zap.Logger) *tls.Config {
+	tlsConfig := utils.Deref(clientConfig.TLSClientConfig, tls.Config{
+		InsecureSkipVerify: true, //nolint:gosec // This is intentional
+	})
+
+	if clientConfig.ClientCert != nil {
+		if certPool, err := utils.GetRotatingCertPool(*clientConfig.ClientCert); err != nil {
+			logger.Warn("failed to load client certificate", zap.Error(err))
+		} else {
+			tlsConfig.GetClientCertificate = certPool.GetClientCertificate
+		}
+	}
+
+	return &tlsConfig
+}
+
 func dialViaProxyFunc(proxyFunc utils.ProxyFunc, network string) fasthttp.DialFunc {
 	// Return closure to select a random proxy on each call
 	return func(addr string) (net.Conn, error) {
@@ -24,6 +24,7 @@ package packetgen
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"time"
@@ -56,6 +57,10 @@ func OpenConnection(c ConnectionConfig) (Connection, error) {
 			return nil, fmt.Errorf("error decoding connection config: %w", err)
 		}
 
+		if cfg.ClientCert != nil && cfg.TLSClientConfig == nil {
+			return nil, errors.New("error decoding connection config: client_cert requires tls_client_config")
+		}
+
 		return openNetConn(cfg, c.Proxy)
 	default:
 		return nil, fmt.Errorf("unknown connection type: %v", c.Type)
@@ -119,6 +124,7 @@ type netConnConfig struct {
 	Timeout         time.Duration
 	Proxy           utils.ProxyParams
 	TLSClientConfig *tls.Config
+	ClientCert      *utils.ClientCertConfig
 }
 
 type netConn struct {
@@ -129,7 +135,7 @@ type netConn struct {
 }
 
 func openNetConn(c netConnConfig, proxyParams *utils.ProxyParams) (*netConn, error) {
-	conn, err := utils.GetProxyFunc(utils.NonNilOrDefault(proxyParams, utils.ProxyParams{}), c.Protocol)(c.Protocol, c.Address)
+	conn, err := utils.GetProxyFunc(utils.Deref(proxyParams, utils.ProxyParams{}), c.Protocol)(c.Protocol, c.Address)
 
 	switch {
 	case err != nil:
@@ -138,7 +144,21 @@ func openNetConn(c netConnConfig, proxyParams *utils.ProxyParams) (*netConn, err
 		return &netConn{Conn: conn, buf: gopacket.NewSerializeBuffer(), target: c.Protocol + "://" + c.Address}, nil
 	}
 
-	tlsConn := tls.Client(conn, c.TLSClientConfig)
+	tlsConfig := c.TLSClientConfig
+
+	if c.ClientCert != nil {
+		certPool, err := utils.GetRotatingCertPool(*c.ClientCert)
+		if err != nil {
+			conn.Close()
+
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+
+		tlsConfig = tlsConfig.Clone()
+		tlsConfig.GetClientCertificate = certPool.GetClientCertificate
+	}
+
+	tlsConn := tls.Client(conn, tlsConfig)
 	if err = tlsConn.Handshake(); err != nil {
 		tlsConn.Close()
 
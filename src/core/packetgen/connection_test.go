@@ -0,0 +1,20 @@
+package packetgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOpenConnectionClientCertWithoutTLS(t *testing.T) {
+	t.Parallel()
+
+	_, err := OpenConnection(ConnectionConfig{Type: "net", Args: map[string]any{
+		"protocol":    "tcp",
+		"address":     "127.0.0.1:1",
+		"client_cert": map[string]any{"cert_file": "cert.pem", "key_file": "key.pem"},
+	}})
+	// the address is unreachable, the config has to be rejected before dialing
+	if err == nil || !strings.Contains(err.Error(), "client_cert") {
+		t.Errorf("expected client_cert without tls_client_config to be rejected, got %v", err)
+	}
+}
@@ -125,7 +125,7 @@ func buildDNSPacket(data map[string]any) (*layers.DNS, error) {
 		RA: c.RA,
 		Z:  c.Z,
 
-		QDCount:   utils.NonNilOrDefault(c.QDCount, uint16(len(c.Questions))),
+		QDCount:   utils.Deref(c.QDCount, uint16(len(c.Questions))),
 		Questions: questions,
 	}, nil
 }
@@ -27,9 +27,11 @@ import (
 	"context"
 	"flag"
 	"math/rand"
+	"net/http"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/schollz/progressbar/v3"
 	"go.uber.org/zap"
 
 	"github.com/Arriven/db1000n/src/job/config"
@@ -51,8 +53,29 @@ type GlobalConfig struct {
 	RandomInterval      time.Duration
 	MinInterval         time.Duration
 	Backoff             utils.BackoffConfig
+	StartupProbe        *config.Config
+	ReadinessProbe      *config.Config
+	LivenessProbe       *config.Config
+	LivenessInterval    time.Duration
+	LivenessThreshold   int
+	DebugTemplates      bool
+	DebugTemplatesPath  string
+	SentinelURL         string
+	SentinelStatus      int
+	SentinelInterval    time.Duration
+	PoisonPillURL       string
+	JobTracePath        string
+	ExportMetricsSchema bool
+	LaunchJitter        time.Duration
+	MaxStackMB          int
+	DebugMode           bool
 }
 
+const (
+	defaultLivenessThreshold = 3
+	defaultSentinelInterval  = time.Minute
+)
+
 // NewGlobalConfigWithFlags returns a GlobalConfig initialized with command line flags.
 func NewGlobalConfigWithFlags() *GlobalConfig {
 	res := GlobalConfig{
@@ -83,6 +106,39 @@ func NewGlobalConfigWithFlags() *GlobalConfig {
 	flag.DurationVar(&res.Backoff.Timeout, "backoff-timeout", utils.GetEnvDurationDefault("BACKOFF_TIMEOUT", utils.DefaultBackoffConfig().Timeout),
 		"initial exponential backoff timeout")
 
+	jobConfigFlag(&res.StartupProbe, "startup-probe", "STARTUP_PROBE",
+		"job config (yaml or json) that has to succeed before any jobs are started")
+	jobConfigFlag(&res.ReadinessProbe, "readiness-probe", "READINESS_PROBE",
+		"job config (yaml or json) that has to succeed before a new config is applied, the current config keeps running otherwise")
+	jobConfigFlag(&res.LivenessProbe, "liveness-probe", "LIVENESS_PROBE",
+		"job config (yaml or json) that is periodically checked, the app exits if it fails too many times in a row")
+	flag.DurationVar(&res.LivenessInterval, "liveness-interval", utils.GetEnvDurationDefault("LIVENESS_INTERVAL", time.Minute),
+		"how often to run the liveness probe")
+	flag.IntVar(&res.LivenessThreshold, "liveness-threshold", utils.GetEnvIntDefault("LIVENESS_THRESHOLD", defaultLivenessThreshold),
+		"how many consecutive liveness probe failures are tolerated")
+	flag.BoolVar(&res.DebugTemplates, "debug-templates", utils.GetEnvBoolDefault("DEBUG_TEMPLATES", false),
+		"record every template render with its input and output, adds noticeable overhead")
+	flag.StringVar(&res.DebugTemplatesPath, "debug-templates-path", utils.GetEnvStringDefault("DEBUG_TEMPLATES_PATH", "templates.jsonl"),
+		"file to append template render records to (only applies if debug-templates is enabled)")
+	flag.StringVar(&res.SentinelURL, "sentinel-url", utils.GetEnvStringDefault("SENTINEL_URL", ""),
+		"url that is periodically polled, all jobs are stopped once it responds with sentinel-status")
+	flag.IntVar(&res.SentinelStatus, "sentinel-status", utils.GetEnvIntDefault("SENTINEL_STATUS", http.StatusOK),
+		"http status of the sentinel url that stops all jobs")
+	flag.DurationVar(&res.SentinelInterval, "sentinel-interval", utils.GetEnvDurationDefault("SENTINEL_INTERVAL", defaultSentinelInterval),
+		"how often to poll the sentinel url, non-positive values fall back to the default")
+	flag.StringVar(&res.PoisonPillURL, "poison-pill-url", utils.GetEnvStringDefault("POISON_PILL_URL", ""),
+		"url that is checked on every config refresh, all jobs are stopped and the app exits once it responds with 200")
+	flag.StringVar(&res.JobTracePath, "job-trace-path", utils.GetEnvStringDefault("JOB_TRACE_PATH", ""),
+		"file to write timings of every job invocation to, it's overwritten on start, see trace-render to visualize it")
+	flag.BoolVar(&res.ExportMetricsSchema, "export-metrics-schema", utils.GetEnvBoolDefault("EXPORT_METRICS_SCHEMA", false),
+		"print names and types of all exported metrics as json and exit")
+	flag.DurationVar(&res.LaunchJitter, "launch-jitter", utils.GetEnvDurationDefault("LAUNCH_JITTER", 0),
+		"delay the start of every job instance by a random duration up to this value so that instances don't run in sync")
+	flag.IntVar(&res.MaxStackMB, "max-stack-mb", utils.GetEnvIntDefault("MAX_STACK_MB", 0),
+		"stop all jobs and log the top goroutine stacks once goroutine stacks use more memory than this, disabled by default")
+	flag.BoolVar(&res.DebugMode, "debug-mode", utils.GetEnvBoolDefault("DEBUG_MODE", false),
+		"enable debug-breakpoint jobs, they are skipped otherwise")
+
 	return &res
 }
 
@@ -98,8 +154,17 @@ func (g GlobalConfig) GetProxyParams(logger *zap.Logger, data any) utils.ProxyPa
 type Job = func(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error)
 
 // Get job by type name
-//nolint:cyclop // The string map alternative is orders of magnitude slower
 func Get(t string) Job {
+	job := get(t)
+	if job == nil || jobTracer == nil {
+		return job
+	}
+
+	return jobTracer.wrap(t, job)
+}
+
+//nolint:cyclop // The string map alternative is orders of magnitude slower
+func get(t string) Job {
 	switch t {
 	case "http", "http-flood":
 		return fastHTTPJob
@@ -113,8 +178,18 @@ func Get(t string) Job {
 		return packetgenJob
 	case "sequence":
 		return sequenceJob
+	case "session-sm":
+		return sessionStateMachineJob
 	case "parallel":
 		return parallelJob
+	case "weighted-random":
+		return weightedRandomJob
+	case "ab-test":
+		return abTestJob
+	case "sticky-session":
+		return stickySessionJob
+	case "debug-breakpoint":
+		return debugBreakpointJob
 	case "log":
 		return logJob
 	case "set-value":
@@ -123,12 +198,26 @@ func Get(t string) Job {
 		return checkJob
 	case "sleep":
 		return sleepJob
+	case "think-time":
+		return thinkTimeJob
 	case "discard-error":
 		return discardErrorJob
+	case "cleanup-on-exit":
+		return cleanupOnExitJob
 	case "timeout":
 		return timeoutJob
 	case "loop":
 		return loopJob
+	case "subprocess":
+		return subprocessJob
+	case "tc-netem":
+		return tcNetemJob
+	case "oauth2-token":
+		return oauth2TokenJob
+	case "mock-target":
+		return mockTargetJob
+	case "checkpoint":
+		return checkpointJob
 	case "lock":
 		return lockJob
 	case "js":
@@ -160,7 +249,16 @@ type BasicJobConfig struct {
 	Interval       *time.Duration
 	RandomInterval time.Duration
 	utils.Counter
-	Backoff *utils.BackoffConfig
+	Backoff         *utils.BackoffConfig
+	MaxRequests     int64         // stop the job after this many requests, zero means unlimited
+	ShowProgress    bool          // render a progress bar if stderr is a terminal and the loop is limited by count or max requests
+	ActivityTimeout time.Duration // stop the job if there was no successful iteration for this long, zero means no limit
+
+	quotaReached    bool
+	progressChecked bool
+	progress        *progressbar.ProgressBar
+	progressID      uint64
+	watchdog        *utils.Watchdog
 }
 
 func (c *BasicJobConfig) FromGlobal(global GlobalConfig) {
@@ -178,7 +276,7 @@ func (c *BasicJobConfig) FromGlobal(global GlobalConfig) {
 }
 
 func (c BasicJobConfig) GetInterval(stable bool) time.Duration {
-	stableInterval := utils.NonNilOrDefault(c.Interval, time.Duration(c.IntervalMs)*time.Millisecond)
+	stableInterval := utils.Deref(c.Interval, time.Duration(c.IntervalMs)*time.Millisecond)
 	if stable {
 		return stableInterval
 	}
@@ -186,7 +284,40 @@ func (c BasicJobConfig) GetInterval(stable bool) time.Duration {
 	return stableInterval + time.Duration(rand.Int63n(utils.Max(c.RandomInterval.Nanoseconds(), 1)))
 }
 
+// WatchActivity returns a context that's cancelled with utils.ErrActivityTimeout if Heartbeat isn't called within ActivityTimeout
+func (c *BasicJobConfig) WatchActivity(ctx context.Context) context.Context {
+	ctx, c.watchdog = utils.NewWatchdog(ctx, c.ActivityTimeout)
+
+	return ctx
+}
+
+// Heartbeat should be called after every successful iteration of the job loop
+func (c *BasicJobConfig) Heartbeat() { c.watchdog.Heartbeat() }
+
+// ActivityErr returns utils.ErrActivityTimeout if the job was stopped due to inactivity
+func (c *BasicJobConfig) ActivityErr() error { return c.watchdog.Err() }
+
 // Next comment for linter
-func (c *BasicJobConfig) Next(ctx context.Context) bool {
-	return utils.Sleep(ctx, c.GetInterval(false)) && c.Counter.Next()
+func (c *BasicJobConfig) Next(ctx context.Context, a *metrics.Accumulator, logger *zap.Logger) bool {
+	if c.MaxRequests > 0 && a.Requests() >= uint64(c.MaxRequests) {
+		if !c.quotaReached {
+			c.quotaReached = true
+			c.finishProgress()
+
+			logger.Info("request quota reached, stopping the job", zap.Int64("max_requests", c.MaxRequests))
+		}
+
+		return false
+	}
+
+	next := utils.Sleep(ctx, c.GetInterval(false)) && c.Counter.Next()
+
+	switch {
+	case next && c.ShowProgress:
+		c.updateProgress(a)
+	case !next:
+		c.finishProgress()
+	}
+
+	return next
 }
@@ -0,0 +1,115 @@
+package job
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/utils"
+	"github.com/Arriven/db1000n/src/utils/metrics"
+)
+
+func TestMaxRequestsQuota(t *testing.T) {
+	t.Parallel()
+
+	const quota = 7
+
+	a := (&metrics.Metrics{}).NewAccumulator("test")
+	cfg := BasicJobConfig{MaxRequests: quota}
+
+	var iterations int
+
+	for cfg.Next(context.Background(), a, zap.NewNop()) {
+		a.Inc("tcp://localhost", metrics.RequestsAttemptedStat)
+
+		iterations++
+
+		if iterations > quota {
+			break
+		}
+	}
+
+	if iterations != quota {
+		t.Errorf("expected loop to stop after %d iterations, got %d", quota, iterations)
+	}
+}
+
+func TestCleanupOnExitRunsAfterError(t *testing.T) {
+	t.Parallel()
+
+	args := map[string]any{
+		"main": map[string]any{"type": "check", "args": map[string]any{"value": "false"}},
+		"cleanup": map[string]any{"type": "log", "args": map[string]any{
+			"text": `{{ shared_map_set "cleanup-test" "main_error" (.Value (ctx_key "main_error")) }}`,
+		}},
+	}
+
+	if _, err := cleanupOnExitJob(context.Background(), args, &GlobalConfig{}, nil, zap.NewNop()); err == nil {
+		t.Error("expected the main job error to be returned")
+	}
+
+	if mainError, _ := utils.GetSharedMap("cleanup-test").Load("main_error"); mainError != "validation failed false" {
+		t.Errorf("expected cleanup to receive the main job error, got %v", mainError)
+	}
+}
+
+func TestCleanupOnExitIsBoundedByTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	args := map[string]any{
+		"main": map[string]any{"type": "set-value", "args": map[string]any{"value": "done"}},
+		// a cleanup that never finishes on its own
+		"cleanup": map[string]any{"type": "loop", "args": map[string]any{
+			"interval_ms": 1,
+			"job":         map[string]any{"type": "sleep", "args": map[string]any{"value": "1ms"}},
+		}},
+		"timeout": "50ms",
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_, _ = cleanupOnExitJob(ctx, args, &GlobalConfig{}, nil, zap.NewNop())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cleanup wasn't stopped by the timeout")
+	}
+}
+
+func TestCheckpointRestoresStateAcrossRestarts(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	args := func(count int) map[string]any {
+		return map[string]any{
+			"checkpoint_file": path,
+			"state_key":       "data.state",
+			"count":           count,
+			"job": map[string]any{"type": "set-value", "name": "state", "args": map[string]any{
+				"value": `{{ or (.Value (ctx_key "data.state")) "" }}x`,
+			}},
+		}
+	}
+
+	// a restart is simulated by running the job again with the same checkpoint file
+	for run, expected := range []string{"xxx", "xxxxx"} {
+		if _, err := checkpointJob(context.Background(), args(3-run), &GlobalConfig{}, nil, zap.NewNop()); err != nil {
+			t.Fatal(err)
+		}
+
+		if state, err := readCheckpoint(path); err != nil || state != expected {
+			t.Errorf("run %d: expected checkpointed state %q, got %v (%v)", run, expected, state, err)
+		}
+	}
+}
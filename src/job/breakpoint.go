@@ -0,0 +1,166 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils"
+	"github.com/Arriven/db1000n/src/utils/metrics"
+	"github.com/Arriven/db1000n/src/utils/templates"
+)
+
+// breakpointMutex makes breakpoints hit concurrently wait for each other
+var breakpointMutex sync.Mutex
+
+// "debug-breakpoint" in config
+// Pauses the job until "continue" is sent to the unix socket at socket_path. Supported commands:
+//
+//	get <key>          prints the context value available to templates as {{ .Value (ctx_key "<key>") }}
+//	set <key> <value>  overrides the value for subsequent get commands
+//	continue           releases the job
+//
+// Context is immutable so overrides are returned as the job data, which is a map of keys to values.
+// Breakpoints are skipped unless debug mode is enabled.
+func debugBreakpointJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (
+	data any, err error,
+) {
+	var jobConfig struct {
+		SocketPath string
+	}
+
+	if err := utils.Decode(args, &jobConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	if !globalConfig.DebugMode {
+		logger.Warn("debug mode is disabled, skipping the breakpoint")
+
+		return nil, nil
+	}
+
+	breakpointMutex.Lock()
+	defer breakpointMutex.Unlock()
+
+	listener, err := net.Listen("unix", jobConfig.SocketPath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening breakpoint socket: %w", err)
+	}
+
+	// closing the listener removes the socket file
+	defer closeOnDone(ctx, listener)()
+
+	logger.Info("breakpoint hit, waiting for continue", zap.String("socket", jobConfig.SocketPath))
+
+	overrides := make(map[string]string)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return overrides, nil
+			}
+
+			return nil, fmt.Errorf("error accepting breakpoint connection: %w", err)
+		}
+
+		// a client that stays connected shouldn't keep the job from stopping
+		closeConn := closeOnDone(ctx, conn)
+		released := serveBreakpoint(ctx, conn, overrides)
+
+		closeConn()
+
+		if released {
+			return overrides, nil
+		}
+	}
+}
+
+// closeOnDone closes c when ctx is done or when the returned function is called, whichever happens first
+func closeOnDone(ctx context.Context, c io.Closer) (closeNow func()) {
+	stop := make(chan struct{})
+	closed := make(chan struct{})
+
+	go func() {
+		defer close(closed)
+
+		select {
+		case <-ctx.Done():
+		case <-stop:
+		}
+
+		c.Close()
+	}()
+
+	return func() {
+		close(stop)
+		<-closed
+	}
+}
+
+// serveBreakpoint handles commands of a single connection until it's closed, returns true if the job should continue
+func serveBreakpoint(ctx context.Context, conn net.Conn, overrides map[string]string) bool {
+	const fields = 3
+
+	scanner := bufio.NewScanner(conn)
+
+	for scanner.Scan() {
+		command := strings.SplitN(strings.TrimSpace(scanner.Text()), " ", fields)
+
+		var reply string
+
+		switch {
+		case command[0] == "continue":
+			fmt.Fprintln(conn, "ok")
+
+			return true
+		case command[0] == "get" && len(command) == 2:
+			if value, ok := overrides[command[1]]; ok {
+				reply = value
+			} else {
+				reply = fmt.Sprint(ctx.Value(templates.ContextKey(command[1])))
+			}
+		case command[0] == "set" && len(command) == fields:
+			overrides[command[1]] = command[2]
+			reply = "ok"
+		default:
+			reply = "unknown command, use: get <key>, set <key> <value> or continue"
+		}
+
+		if _, err := fmt.Fprintln(conn, reply); err != nil && !errors.Is(err, net.ErrClosed) {
+			return false
+		}
+	}
+
+	return false
+}
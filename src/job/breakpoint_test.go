@@ -0,0 +1,123 @@
+package job
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/utils/templates"
+)
+
+func TestDebugBreakpointJob(t *testing.T) {
+	t.Parallel()
+
+	socketPath := filepath.Join(t.TempDir(), "bp.sock")
+	ctx := context.WithValue(context.Background(), templates.ContextKey("target"), "localhost")
+
+	type result struct {
+		data any
+		err  error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		data, err := debugBreakpointJob(ctx, map[string]any{"socket_path": socketPath}, &GlobalConfig{DebugMode: true}, nil, zap.NewNop())
+		done <- result{data: data, err: err}
+	}()
+
+	conn := dialBreakpoint(t, socketPath)
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for _, step := range []struct{ command, reply string }{
+		{command: "get target", reply: "localhost"},
+		{command: "set target example.com", reply: "ok"},
+		{command: "get target", reply: "example.com"},
+		{command: "continue", reply: "ok"},
+	} {
+		fmt.Fprintln(conn, step.command)
+
+		if reply, err := reader.ReadString('\n'); err != nil || reply != step.reply+"\n" {
+			t.Fatalf("%q: expected reply %q, got %q (%v)", step.command, step.reply, reply, err)
+		}
+	}
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("unexpected error: %v", res.err)
+	}
+
+	if expected := map[string]string{"target": "example.com"}; !reflect.DeepEqual(res.data, expected) {
+		t.Errorf("expected data %v, got %v", expected, res.data)
+	}
+}
+
+func TestDebugBreakpointJobStopsOnCancelWithClientConnected(t *testing.T) {
+	t.Parallel()
+
+	socketPath := filepath.Join(t.TempDir(), "bp.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error, 1)
+
+	go func() {
+		_, err := debugBreakpointJob(ctx, map[string]any{"socket_path": socketPath}, &GlobalConfig{DebugMode: true}, nil, zap.NewNop())
+		done <- err
+	}()
+
+	conn := dialBreakpoint(t, socketPath)
+	defer conn.Close()
+
+	// make sure the connection is being served before cancelling
+	fmt.Fprintln(conn, "get target")
+
+	if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+		t.Fatalf("error reading reply: %v", err)
+	}
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("breakpoint didn't stop while a client was connected")
+	}
+
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("socket wasn't removed: %v", err)
+	}
+}
+
+func dialBreakpoint(t *testing.T, socketPath string) net.Conn {
+	t.Helper()
+
+	var (
+		conn net.Conn
+		err  error
+	)
+
+	for i := 0; i < 50; i++ {
+		if conn, err = net.Dial("unix", socketPath); err == nil {
+			return conn
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("error connecting to breakpoint: %v", err)
+
+	return nil
+}
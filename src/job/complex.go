@@ -24,13 +24,20 @@ package job
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils"
 	"github.com/Arriven/db1000n/src/utils/metrics"
 	"github.com/Arriven/db1000n/src/utils/templates"
 )
@@ -110,3 +117,279 @@ func parallelJob(ctx context.Context, args config.Args, globalConfig *GlobalConf
 
 	return nil, nil
 }
+
+// "weighted-random" in config
+func weightedRandomJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
+	var jobConfig struct {
+		BasicJobConfig
+
+		Jobs []struct {
+			Job    config.Config
+			Weight float64
+		}
+	}
+
+	if err := ParseConfig(&jobConfig, args, *globalConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	cumulativeWeights := make([]float64, len(jobConfig.Jobs))
+
+	var total float64
+
+	for i := range jobConfig.Jobs {
+		if jobConfig.Jobs[i].Weight < 0 {
+			return nil, fmt.Errorf("negative weight %v for job %q", jobConfig.Jobs[i].Weight, jobConfig.Jobs[i].Job.Type)
+		}
+
+		total += jobConfig.Jobs[i].Weight
+		cumulativeWeights[i] = total
+	}
+
+	if total <= 0 {
+		return nil, fmt.Errorf("no jobs with positive weight")
+	}
+
+	// point is picked from (0, total] so that jobs with zero weight are never selected
+	point := total - rand.Float64()*total //nolint:gosec // Cryptographically secure random not required
+	selected := jobConfig.Jobs[sort.SearchFloat64s(cumulativeWeights, point)].Job
+
+	job := Get(selected.Type)
+	if job == nil {
+		return nil, fmt.Errorf("unknown job %q", selected.Type)
+	}
+
+	return job(ctx, selected.Args, globalConfig, a, logger)
+}
+
+// "ab-test" in config
+// Routes the key to JobA or JobB, the selected variant ("a" or "b") is available to inner job templates
+// as {{ .Value (ctx_key "ab_variant") }} so that their requests and stats can be told apart.
+func abTestJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
+	var jobConfig struct {
+		BasicJobConfig
+
+		Key      string
+		SplitPct float64 // percentage of keys routed to JobA
+		JobA     config.Config
+		JobB     config.Config
+	}
+
+	if err := ParseConfig(&jobConfig, args, *globalConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	const hundred = 100
+
+	variant, selected := "b", jobConfig.JobB
+	if hashFraction(templates.ParseAndExecute(logger, jobConfig.Key, ctx))*hundred < jobConfig.SplitPct {
+		variant, selected = "a", jobConfig.JobA
+	}
+
+	ctx = context.WithValue(ctx, templates.ContextKey("ab_variant"), variant)
+
+	job := Get(selected.Type)
+	if job == nil {
+		return nil, fmt.Errorf("unknown job %q", selected.Type)
+	}
+
+	return job(ctx, selected.Args, globalConfig, a, logger)
+}
+
+// hashFraction deterministically maps key to [0, 1) using FNV-1a
+func hashFraction(key string) float64 {
+	// keep only as many bits as float64 mantissa can hold so that the result never rounds up to 1
+	const mantissaBits = 53
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	return float64(h.Sum64()>>(64-mantissaBits)) / (1 << mantissaBits)
+}
+
+const defaultStickySessionTTL = 5 * time.Minute
+
+type stickySession struct {
+	job     int
+	expires time.Time
+}
+
+// stickySessionKey includes a hash of the jobs list as sessions store indices into it
+type stickySessionKey struct {
+	jobs    uint64
+	session string
+}
+
+// stickySessions is shared by all sticky-session jobs. Safe for concurrent use.
+var stickySessions stickySessionStore
+
+type stickySessionStore struct {
+	sessions utils.SyncMap[stickySessionKey, stickySession]
+	inserts  uint64
+}
+
+// get returns the job index pinned to the key, a new random one is pinned if there's none or it has expired
+func (s *stickySessionStore) get(key stickySessionKey, jobs int, ttl time.Duration) int {
+	const sweepEvery = 1024
+
+	now := time.Now()
+	session := stickySession{job: rand.Intn(jobs), expires: now.Add(ttl)} //nolint:gosec // Cryptographically secure random not required
+
+	existing, loaded := s.sessions.LoadOrStore(key, session)
+	if loaded && now.Before(existing.expires) {
+		return existing.job
+	}
+
+	if loaded {
+		s.sessions.Store(key, session)
+	}
+
+	if atomic.AddUint64(&s.inserts, 1)%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	return session.job
+}
+
+// sweep drops expired sessions to keep memory bounded when session keys are unique
+func (s *stickySessionStore) sweep(now time.Time) {
+	s.sessions.Range(func(key stickySessionKey, session stickySession) bool {
+		if !now.Before(session.expires) {
+			s.sessions.Delete(key)
+		}
+
+		return true
+	})
+}
+
+func hashJobConfigs(jobs []config.Config) (uint64, error) {
+	encoded, err := json.Marshal(jobs)
+	if err != nil {
+		return 0, err
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(encoded)
+
+	return h.Sum64(), nil
+}
+
+// "sticky-session" in config
+// Picks a random job for every session key and keeps running it for the same key until ttl (5m by default) passes.
+func stickySessionJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
+	var jobConfig struct {
+		BasicJobConfig
+
+		SessionKey string
+		TTL        time.Duration
+		Jobs       []config.Config
+	}
+
+	if err := ParseConfig(&jobConfig, args, *globalConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	if len(jobConfig.Jobs) == 0 {
+		return nil, fmt.Errorf("no jobs to choose from")
+	}
+
+	if jobConfig.TTL <= 0 {
+		jobConfig.TTL = defaultStickySessionTTL
+	}
+
+	jobsHash, err := hashJobConfigs(jobConfig.Jobs)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing jobs: %w", err)
+	}
+
+	key := stickySessionKey{jobs: jobsHash, session: templates.ParseAndExecute(logger, jobConfig.SessionKey, ctx)}
+	selected := jobConfig.Jobs[stickySessions.get(key, len(jobConfig.Jobs), jobConfig.TTL)]
+
+	job := Get(selected.Type)
+	if job == nil {
+		return nil, fmt.Errorf("unknown job %q", selected.Type)
+	}
+
+	return job(ctx, selected.Args, globalConfig, a, logger)
+}
+
+// "session-sm" in config
+// Runs a state machine where every state is a job and its result (formatted as string) picks the next state.
+// Transitions may have a "default" key used when the result doesn't match any other key, the special "exit" state stops the machine.
+// Returns names of the last visited states.
+func sessionStateMachineJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (
+	data any, err error,
+) {
+	const (
+		exitState         = "exit"
+		defaultTransition = "default"
+		maxVisitedStates  = 100
+	)
+
+	var jobConfig struct {
+		BasicJobConfig
+
+		InitialState string
+		States       []struct {
+			Name        string
+			Job         config.Config
+			Transitions map[string]string
+		}
+	}
+
+	if err := ParseConfig(&jobConfig, args, *globalConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	states := make(map[string]int, len(jobConfig.States))
+	for i, state := range jobConfig.States {
+		states[state.Name] = i
+	}
+
+	// the machine can loop forever so only the tail of the path is kept
+	visited := utils.NewCircularBuffer[string](maxVisitedStates)
+	// results are kept by name and the context is rebuilt from them so that it doesn't grow with every transition
+	results := make(map[string]any)
+
+	for current := jobConfig.InitialState; current != exitState; {
+		if ctx.Err() != nil {
+			return visited.Slice(), nil
+		}
+
+		i, ok := states[current]
+		if !ok {
+			return visited.Slice(), fmt.Errorf("unknown state %q", current)
+		}
+
+		state := jobConfig.States[i]
+		visited.Push(state.Name)
+
+		job := Get(state.Job.Type)
+		if job == nil {
+			return visited.Slice(), fmt.Errorf("unknown job %q", state.Job.Type)
+		}
+
+		stateCtx := context.WithValue(ctx, templates.ContextKey("state"), state.Name)
+		for name, result := range results {
+			stateCtx = context.WithValue(stateCtx, templates.ContextKey("data."+name), result)
+		}
+
+		result, err := job(stateCtx, state.Job.Args, globalConfig, a, logger)
+		if err != nil {
+			return visited.Slice(), fmt.Errorf("error running job in state %q: %w", state.Name, err)
+		}
+
+		results[state.Job.Name] = result
+
+		next, ok := state.Transitions[fmt.Sprint(result)]
+		if !ok {
+			if next, ok = state.Transitions[defaultTransition]; !ok {
+				return visited.Slice(), fmt.Errorf("no transition from state %q for result %q", state.Name, fmt.Sprint(result))
+			}
+		}
+
+		current = next
+	}
+
+	return visited.Slice(), nil
+}
@@ -0,0 +1,245 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/job/config"
+)
+
+func TestSessionStateMachineLoginFlow(t *testing.T) {
+	t.Parallel()
+
+	state := func(name, result string, transitions map[string]any) map[string]any {
+		return map[string]any{
+			"name":        name,
+			"job":         map[string]any{"type": "set-value", "name": name, "args": map[string]any{"value": result}},
+			"transitions": transitions,
+		}
+	}
+
+	args := config.Args{
+		"initial_state": "login",
+		"states": []any{
+			// the action result depends on the login result to make sure data of previous states is available
+			state("login", "token", map[string]any{"token": "action", "default": "exit"}),
+			state("action", `{{ .Value (ctx_key "data.login") }}-used`, map[string]any{"token-used": "logout"}),
+			state("logout", "bye", map[string]any{"default": "exit"}),
+		},
+	}
+
+	visited, err := sessionStateMachineJob(context.Background(), args, &GlobalConfig{}, nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"login", "action", "logout"}; !reflect.DeepEqual(visited, expected) {
+		t.Errorf("expected states %v, got %v", expected, visited)
+	}
+}
+
+func TestSessionStateMachineBoundsVisitedStates(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	args := config.Args{
+		"initial_state": "loop",
+		"states": []any{map[string]any{
+			"name":        "loop",
+			"job":         map[string]any{"type": "set-value", "name": "loop", "args": map[string]any{"value": "again"}},
+			"transitions": map[string]any{"default": "loop"},
+		}},
+	}
+
+	visited, err := sessionStateMachineJob(ctx, args, &GlobalConfig{}, nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if states, ok := visited.([]string); !ok || len(states) != 100 {
+		t.Errorf("expected the last 100 states to be kept, got %d", len(states))
+	}
+}
+
+func stickySessionArgs(sessionKey string, ttl string, values ...string) config.Args {
+	jobs := make([]any, 0, len(values))
+	for _, value := range values {
+		jobs = append(jobs, map[string]any{"type": "set-value", "args": map[string]any{"value": value}})
+	}
+
+	return config.Args{"session_key": sessionKey, "ttl": ttl, "jobs": jobs}
+}
+
+func TestStickySessionKeepsJobForKey(t *testing.T) {
+	t.Parallel()
+
+	// zero ttl falls back to the default instead of disabling stickiness
+	args := stickySessionArgs(t.Name(), "0s", "a", "b", "c", "d", "e", "f", "g", "h")
+
+	first, err := stickySessionJob(context.Background(), args, &GlobalConfig{}, nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 20; i++ {
+		if data, err := stickySessionJob(context.Background(), args, &GlobalConfig{}, nil, zap.NewNop()); err != nil || data != first {
+			t.Fatalf("expected session to stick to %v, got %v (%v)", first, data, err)
+		}
+	}
+}
+
+func TestStickySessionIsScopedToJobsList(t *testing.T) {
+	t.Parallel()
+
+	// pin many keys to a long list so that some of them point past the end of the short one
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("%s-%d", t.Name(), i)
+
+		if _, err := stickySessionJob(context.Background(), stickySessionArgs(key, "1m", "a", "b", "c", "d"), &GlobalConfig{}, nil, zap.NewNop()); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := stickySessionJob(context.Background(), stickySessionArgs(key, "1m", "short"), &GlobalConfig{}, nil, zap.NewNop())
+		if err != nil || data != "short" {
+			t.Fatalf("expected the only job of the short list to run, got %v (%v)", data, err)
+		}
+	}
+}
+
+func TestStickySessionStoreSweepsExpiredSessions(t *testing.T) {
+	t.Parallel()
+
+	var store stickySessionStore
+
+	store.get(stickySessionKey{session: "expired"}, 1, time.Millisecond)
+	store.get(stickySessionKey{session: "alive"}, 1, time.Hour)
+
+	store.sweep(time.Now().Add(time.Second))
+
+	if _, ok := store.sessions.Load(stickySessionKey{session: "expired"}); ok {
+		t.Error("expired session wasn't swept")
+	}
+
+	if _, ok := store.sessions.Load(stickySessionKey{session: "alive"}); !ok {
+		t.Error("active session was swept")
+	}
+}
+
+func weightedRandomArgs(weights map[string]float64) config.Args {
+	jobs := make([]any, 0, len(weights))
+	for value, weight := range weights {
+		jobs = append(jobs, map[string]any{"weight": weight, "job": map[string]any{"type": "set-value", "args": map[string]any{"value": value}}})
+	}
+
+	return config.Args{"jobs": jobs}
+}
+
+func TestWeightedRandomJobDistribution(t *testing.T) {
+	t.Parallel()
+
+	const runs = 10000
+
+	weights := map[string]float64{"get": 70, "post": 20, "delete": 10, "never": 0}
+	args := weightedRandomArgs(weights)
+	counts := make(map[any]int)
+
+	for i := 0; i < runs; i++ {
+		data, err := weightedRandomJob(context.Background(), args, &GlobalConfig{}, nil, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counts[data]++
+	}
+
+	// the allowed deviation is several times the standard deviation so the test doesn't flake
+	const tolerance = 0.03
+
+	for value, weight := range weights {
+		if share := float64(counts[value]) / runs; share < weight/100-tolerance || share > weight/100+tolerance {
+			t.Errorf("expected %q to be picked %v%% of times, got %v%%", value, weight, share*100)
+		}
+	}
+
+	if counts["never"] != 0 {
+		t.Errorf("job with zero weight was picked %d times", counts["never"])
+	}
+}
+
+func TestWeightedRandomJobInvalidWeights(t *testing.T) {
+	t.Parallel()
+
+	for name, weights := range map[string]map[string]float64{
+		"negative": {"a": 1, "b": -1},
+		"all zero": {"a": 0, "b": 0},
+		"empty":    {},
+	} {
+		if _, err := weightedRandomJob(context.Background(), weightedRandomArgs(weights), &GlobalConfig{}, nil, zap.NewNop()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func abTestArgs(key string, splitPct float64) config.Args {
+	// both variants report the variant label they see so the test can check it matches the selected job
+	variant := func(name string) map[string]any {
+		return map[string]any{"type": "set-value", "args": map[string]any{"value": name + `:{{ .Value (ctx_key "ab_variant") }}`}}
+	}
+
+	return config.Args{"key": key, "split_pct": splitPct, "job_a": variant("A"), "job_b": variant("B")}
+}
+
+func TestABTestJobSplit(t *testing.T) {
+	t.Parallel()
+
+	const (
+		keys     = 10000
+		splitPct = 30
+	)
+
+	counts := make(map[any]int)
+
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("session-%d", i)
+
+		data, err := abTestJob(context.Background(), abTestArgs(key, splitPct), &GlobalConfig{}, nil, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// routing is deterministic
+		if again, _ := abTestJob(context.Background(), abTestArgs(key, splitPct), &GlobalConfig{}, nil, zap.NewNop()); again != data {
+			t.Fatalf("key %q was routed to %v and then to %v", key, data, again)
+		}
+
+		counts[data]++
+	}
+
+	if len(counts) != 2 {
+		t.Fatalf("expected only the two variants labelled as a and b, got %v", counts)
+	}
+
+	if share := float64(counts["A:a"]) / keys * 100; share < splitPct-3 || share > splitPct+3 {
+		t.Errorf("expected about %v%% of keys to be routed to A, got %v%%", splitPct, share)
+	}
+}
+
+func TestABTestJobSplitBounds(t *testing.T) {
+	t.Parallel()
+
+	for splitPct, expected := range map[float64]string{0: "B:b", 100: "A:a"} {
+		for i := 0; i < 100; i++ {
+			data, err := abTestJob(context.Background(), abTestArgs(fmt.Sprint(i), splitPct), &GlobalConfig{}, nil, zap.NewNop())
+			if err != nil || data != expected {
+				t.Fatalf("split %v: expected %v, got %v (%v)", splitPct, expected, data, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// ContentHashHeader is the response header servers can use to advertise the sha256 of the config body
+const ContentHashHeader = "X-Content-SHA256"
+
+// ContentAddressableCache stores fetched configs by the sha256 of their body.
+// Concurrency-safe.
+type ContentAddressableCache struct {
+	mutex   sync.Mutex
+	entries map[string]RawMultiConfig
+	order   []string // hashes from oldest to newest for eviction
+}
+
+// configs change rarely so there is no point in keeping more than a handful of them around
+const maxCacheEntries = 16
+
+var configCache = NewContentAddressableCache()
+
+// NewContentAddressableCache returns an empty cache.
+func NewContentAddressableCache() *ContentAddressableCache {
+	return &ContentAddressableCache{entries: make(map[string]RawMultiConfig)}
+}
+
+// Hash returns the key the body is stored under.
+func Hash(body []byte) string {
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns a copy of the config stored under hash.
+func (c *ContentAddressableCache) Get(hash string) (*RawMultiConfig, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	config, ok := c.entries[hash]
+	if !ok {
+		return nil, false
+	}
+
+	return &config, true
+}
+
+// Put stores a copy of the config under the hash of its body.
+func (c *ContentAddressableCache) Put(config *RawMultiConfig) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	hash := Hash(config.Body)
+	if _, ok := c.entries[hash]; !ok {
+		if len(c.order) >= maxCacheEntries {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+
+		c.order = append(c.order, hash)
+	}
+
+	c.entries[hash] = *config
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// configServer serves body and counts requests by method
+type configServer struct {
+	mutex      sync.Mutex
+	body       string
+	headerHash string // advertised in ContentHashHeader if not empty
+	requests   map[string]int
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.requests[r.Method]++
+
+	if s.headerHash != "" {
+		w.Header().Set(ContentHashHeader, s.headerHash)
+	}
+
+	if r.Method == http.MethodGet {
+		_, _ = w.Write([]byte(s.body))
+	}
+}
+
+func (s *configServer) count(method string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests[method]
+}
+
+func (s *configServer) update(body string, advertise bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.body, s.headerHash = body, ""
+	if advertise {
+		s.headerHash = Hash([]byte(body))
+	}
+}
+
+func startConfigServer(t *testing.T, body string, advertise bool) (*configServer, *url.URL) {
+	t.Helper()
+
+	handler := &configServer{requests: make(map[string]int)}
+	handler.update(body, advertise)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return handler, serverURL
+}
+
+func fetchBody(t *testing.T, configURL *url.URL) string {
+	t.Helper()
+
+	config, err := fetchURL(configURL, &RawMultiConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(config.Body)
+}
+
+func TestFetchURLCacheHit(t *testing.T) {
+	t.Parallel()
+
+	server, configURL := startConfigServer(t, "jobs: [] # cache hit", true)
+
+	for i := 0; i < 3; i++ {
+		if body := fetchBody(t, configURL); body != "jobs: [] # cache hit" {
+			t.Fatalf("unexpected body %q", body)
+		}
+	}
+
+	if gets, heads := server.count(http.MethodGet), server.count(http.MethodHead); gets != 1 || heads != 2 {
+		t.Errorf("expected 1 GET and 2 HEAD requests, got %d and %d", gets, heads)
+	}
+}
+
+func TestFetchURLHashMismatch(t *testing.T) {
+	t.Parallel()
+
+	server, configURL := startConfigServer(t, "jobs: [] # before", true)
+	fetchBody(t, configURL)
+
+	server.update("jobs: [] # after", true)
+
+	if body := fetchBody(t, configURL); body != "jobs: [] # after" {
+		t.Errorf("expected the changed config to be downloaded, got %q", body)
+	}
+
+	if gets := server.count(http.MethodGet); gets != 2 {
+		t.Errorf("expected 2 GET requests, got %d", gets)
+	}
+}
+
+func TestFetchURLWithoutHashHeader(t *testing.T) {
+	t.Parallel()
+
+	server, configURL := startConfigServer(t, "jobs: [] # no header", false)
+
+	for i := 0; i < 3; i++ {
+		fetchBody(t, configURL)
+	}
+
+	if gets, heads := server.count(http.MethodGet), server.count(http.MethodHead); gets != 3 || heads != 0 {
+		t.Errorf("expected 3 GET and no HEAD requests, got %d and %d", gets, heads)
+	}
+}
+
+func TestContentAddressableCacheEvictsOldest(t *testing.T) {
+	t.Parallel()
+
+	cache := NewContentAddressableCache()
+
+	for i := 0; i <= maxCacheEntries; i++ {
+		cache.Put(&RawMultiConfig{Body: []byte{byte(i)}})
+	}
+
+	if _, ok := cache.Get(Hash([]byte{0})); ok {
+		t.Error("expected the oldest entry to be evicted")
+	}
+
+	for i := 1; i <= maxCacheEntries; i++ {
+		if _, ok := cache.Get(Hash([]byte{byte(i)})); !ok {
+			t.Errorf("entry %d was evicted", i)
+		}
+	}
+}
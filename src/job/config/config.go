@@ -31,6 +31,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -43,16 +44,29 @@ type Args = map[string]any
 
 // Config for a single job.
 type Config struct {
-	Name   string
-	Type   string
-	Count  int
-	Filter string
-	Args   Args
+	Name           string
+	Type           string
+	Count          int
+	Filter         string            // go template or JSON Logic rule, the job is skipped unless it evaluates to true
+	Labels         map[string]string // values are templates evaluated on the client
+	Selector       string            // kubernetes-style label selector evaluated against Labels
+	IsolationMode  string            `yaml:"isolation_mode"`
+	MinHealthScore float64           `yaml:"min_health_score"` // 0-100, zero disables health checks
+	OnUnhealthy    *Config           `yaml:"on_unhealthy"`     // job to run when health score drops below MinHealthScore
+	Args           Args
 }
 
 // MultiConfig for all jobs.
 type MultiConfig struct {
-	Jobs []Config
+	Jobs          []Config
+	ResourceQuota *ResourceQuota `yaml:"resource_quota"`
+	DependsOnURL  string         `yaml:"depends_on_url"`
+}
+
+// ResourceQuota limits resources the client is allowed to use, zero values mean no limit.
+type ResourceQuota struct {
+	MaxMemoryMB float64 `yaml:"max_memory_mb"`
+	MaxCPUPct   float64 `yaml:"max_cpu_pct"`
 }
 
 type RawMultiConfig struct {
@@ -111,6 +125,10 @@ func fetchAndDecrypt(logger *zap.Logger, path string, lastKnownConfig *RawMultiC
 
 // fetchSingle reads a config from a single source
 func fetchSingle(path string, lastKnownConfig *RawMultiConfig) (*RawMultiConfig, error) {
+	if IsConfigMapURL(path) {
+		return fetchConfigMap(path)
+	}
+
 	configURL, err := url.ParseRequestURI(path)
 	// absolute paths can be interpreted as a URL with no schema, need to check for that explicitly
 	if err != nil || filepath.IsAbs(path) {
@@ -131,6 +149,13 @@ func fetchURL(configURL *url.URL, lastKnownConfig *RawMultiConfig) (*RawMultiCon
 	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
 	defer cancel()
 
+	// don't waste a request on servers that never advertised a hash
+	if _, ok := hashAdvertisers.Load(configURL.String()); ok {
+		if cached, ok := fetchCached(ctx, configURL); ok {
+			return cached, nil
+		}
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configURL.String(), nil)
 	if err != nil {
 		return nil, err
@@ -167,7 +192,41 @@ func fetchURL(configURL *url.URL, lastKnownConfig *RawMultiConfig) (*RawMultiCon
 		return nil, err
 	}
 
-	return &RawMultiConfig{Body: res, etag: etag, lastModified: lastModified}, nil
+	if resp.Header.Get(ContentHashHeader) != "" {
+		hashAdvertisers.Store(configURL.String(), struct{}{})
+	} else {
+		hashAdvertisers.Delete(configURL.String())
+	}
+
+	config := &RawMultiConfig{Body: res, etag: etag, lastModified: lastModified}
+	configCache.Put(config)
+
+	return config, nil
+}
+
+// hashAdvertisers holds urls that sent ContentHashHeader in the last response
+var hashAdvertisers utils.SyncMap[string, struct{}]
+
+// fetchCached checks whether the server advertises a hash of a config that's already cached to avoid downloading it again
+func fetchCached(ctx context.Context, configURL *url.URL) (*RawMultiConfig, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, configURL.String(), nil)
+	if err != nil {
+		return nil, false
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, false
+	}
+
+	resp.Body.Close()
+
+	hash := resp.Header.Get(ContentHashHeader)
+	if resp.StatusCode != http.StatusOK || hash == "" {
+		return nil, false
+	}
+
+	return configCache.Get(strings.ToLower(hash))
 }
 
 // FetchRawMultiConfig retrieves the current config using a list of paths. Falls back to the last known config in case of errors.
@@ -2,6 +2,9 @@ package config
 
 import (
 	"encoding/base64"
+	"fmt"
+
+	"github.com/Arriven/db1000n/src/utils"
 )
 
 // DefaultConfig is the config embedded into the app that it will use if not able to fetch any other config
@@ -11,8 +14,8 @@ var DefaultConfig = ``
 func init() {
 	decoded, err := base64.StdEncoding.DecodeString(DefaultConfig)
 	if err != nil {
-		panic("Can't decode base64 encoded encrypted config")
+		err = fmt.Errorf("can't decode base64 encoded default config: %w", err)
 	}
 
-	DefaultConfig = string(decoded)
+	DefaultConfig = string(utils.Must(decoded, err))
 }
@@ -0,0 +1,248 @@
+package config
+
+// ConfigMap config source.
+//
+// Paths like k8s://namespace/configmap-name/data-key are read from the Kubernetes API using the in-cluster service account.
+// It's a minimal REST client rather than client-go to keep the dependency tree small.
+//
+// The service account needs the following RBAC permissions (watch falls back to polling without it):
+//
+//	apiVersion: rbac.authorization.k8s.io/v1
+//	kind: Role
+//	metadata:
+//	  name: db1000n-config-reader
+//	  namespace: <namespace>
+//	rules:
+//	  - apiGroups: [""]
+//	    resources: ["configmaps"]
+//	    resourceNames: ["<configmap-name>"]
+//	    verbs: ["get", "list", "watch"]
+//	---
+//	apiVersion: rbac.authorization.k8s.io/v1
+//	kind: RoleBinding
+//	metadata:
+//	  name: db1000n-config-reader
+//	  namespace: <namespace>
+//	subjects:
+//	  - kind: ServiceAccount
+//	    name: <service-account>
+//	    namespace: <namespace>
+//	roleRef:
+//	  apiGroup: rbac.authorization.k8s.io
+//	  kind: Role
+//	  name: db1000n-config-reader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const configMapScheme = "k8s://"
+
+const (
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAPath    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// IsConfigMapURL reports whether path points to a Kubernetes ConfigMap
+func IsConfigMapURL(path string) bool { return strings.HasPrefix(path, configMapScheme) }
+
+type configMapRef struct {
+	namespace string
+	name      string
+	key       string
+}
+
+func parseConfigMapURL(path string) (configMapRef, error) {
+	const parts = 3
+
+	fields := strings.Split(strings.TrimPrefix(path, configMapScheme), "/")
+	if !IsConfigMapURL(path) || len(fields) != parts || fields[0] == "" || fields[1] == "" || fields[2] == "" {
+		return configMapRef{}, fmt.Errorf("invalid configmap url %q, expected %snamespace/name/key", path, configMapScheme)
+	}
+
+	return configMapRef{namespace: fields[0], name: fields[1], key: fields[2]}, nil
+}
+
+type configMap struct {
+	Data       map[string]string `json:"data"`
+	BinaryData map[string][]byte `json:"binaryData"`
+}
+
+func (cm configMap) value(key string) ([]byte, bool) {
+	if value, ok := cm.Data[key]; ok {
+		return []byte(value), true
+	}
+
+	value, ok := cm.BinaryData[key]
+
+	return value, ok
+}
+
+type inClusterClient struct {
+	host   string
+	token  string
+	client *http.Client
+}
+
+func newInClusterClient() (*inClusterClient, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("not running in a kubernetes cluster")
+	}
+
+	token, err := os.ReadFile(serviceAccountTokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading service account token: %w", err)
+	}
+
+	ca, err := os.ReadFile(serviceAccountCAPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading service account ca: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("invalid service account ca")
+	}
+
+	return &inClusterClient{
+		host:  "https://" + net.JoinHostPort(host, port),
+		token: strings.TrimSpace(string(token)),
+		client: &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}},
+	}, nil
+}
+
+func (c *inClusterClient) do(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.host+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, fmt.Errorf("kubernetes api responded with code %d", resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
+func configMapsPath(namespace string) string {
+	return "/api/v1/namespaces/" + url.PathEscape(namespace) + "/configmaps"
+}
+
+func fetchConfigMap(path string) (*RawMultiConfig, error) {
+	const requestTimeout = 20 * time.Second
+
+	ref, err := parseConfigMapURL(path)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newInClusterClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	resp, err := client.do(ctx, configMapsPath(ref.namespace)+"/"+url.PathEscape(ref.name), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var cm configMap
+	if err := json.NewDecoder(resp.Body).Decode(&cm); err != nil {
+		return nil, err
+	}
+
+	value, ok := cm.value(ref.key)
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in configmap %s/%s", ref.key, ref.namespace, ref.name)
+	}
+
+	return &RawMultiConfig{Body: value}, nil
+}
+
+// WatchConfigMap calls onChange whenever the data key referenced by path changes until ctx is done.
+// Returns an error if the watch can't be established, callers are expected to fall back to polling.
+func WatchConfigMap(ctx context.Context, path string, onChange func()) error {
+	ref, err := parseConfigMapURL(path)
+	if err != nil {
+		return err
+	}
+
+	client, err := newInClusterClient()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{"watch": {"true"}, "fieldSelector": {"metadata.name=" + ref.name}}
+
+	var last []byte
+
+	for ctx.Err() == nil {
+		resp, err := client.do(ctx, configMapsPath(ref.namespace), query)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		decoder := json.NewDecoder(resp.Body)
+
+		for {
+			var event struct {
+				Type   string    `json:"type"`
+				Object configMap `json:"object"`
+			}
+
+			if err := decoder.Decode(&event); err != nil {
+				break // the server closes watches periodically, reconnect
+			}
+
+			value, _ := event.Object.value(ref.key)
+			if event.Type == "DELETED" || bytes.Equal(value, last) {
+				continue
+			}
+
+			// the first event is the current state which is fetched by the regular refresh anyway
+			if last != nil {
+				onChange()
+			}
+
+			last = value
+		}
+
+		resp.Body.Close()
+	}
+
+	return nil
+}
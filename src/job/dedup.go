@@ -0,0 +1,113 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// requestDedup is shared by all http jobs so that concurrent loops don't send the same request at the same time
+var requestDedup requestDeduplicator
+
+type requestFingerprint [sha256.Size]byte
+
+type dedupEntry struct {
+	sent    time.Time
+	expires time.Time // the longest window any job checked the entry with, it can't be swept before that
+}
+
+// requestDeduplicator remembers when requests were last sent. Safe for concurrent use.
+type requestDeduplicator struct {
+	mutex   sync.Mutex
+	entries map[requestFingerprint]dedupEntry
+	inserts uint64
+}
+
+// reserve marks the request as sent and returns zero unless an identical request was sent within the window,
+// in which case it returns how long to wait until the window passes
+func (d *requestDeduplicator) reserve(req *fasthttp.Request, window time.Duration) time.Duration {
+	const sweepEvery = 1024
+
+	fingerprint := fingerprintRequest(req)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+	entry, ok := d.entries[fingerprint]
+
+	if ok && now.Sub(entry.sent) < window {
+		if until := entry.sent.Add(window); until.After(entry.expires) {
+			entry.expires = until
+			d.entries[fingerprint] = entry
+		}
+
+		return entry.sent.Add(window).Sub(now)
+	}
+
+	if d.entries == nil {
+		d.entries = make(map[requestFingerprint]dedupEntry)
+	}
+
+	entry.sent = now
+	if until := now.Add(window); until.After(entry.expires) {
+		entry.expires = until
+	}
+
+	d.entries[fingerprint] = entry
+
+	if d.inserts++; d.inserts%sweepEvery == 0 {
+		d.sweep(now)
+	}
+
+	return 0
+}
+
+// sweep drops expired fingerprints to keep memory bounded when requests are dynamic, has to be called with the mutex held
+func (d *requestDeduplicator) sweep(now time.Time) {
+	for fingerprint, entry := range d.entries {
+		if !now.Before(entry.expires) {
+			delete(d.entries, fingerprint)
+		}
+	}
+}
+
+func fingerprintRequest(req *fasthttp.Request) requestFingerprint {
+	hash := sha256.New()
+
+	hash.Write(req.Header.Method())
+	hash.Write([]byte{0})
+	hash.Write(req.URI().FullURI())
+	hash.Write([]byte{0})
+	hash.Write(req.Body())
+
+	var fingerprint requestFingerprint
+
+	copy(fingerprint[:], hash.Sum(nil))
+
+	return fingerprint
+}
@@ -0,0 +1,114 @@
+package job
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestRequestDeduplicatorConcurrent(t *testing.T) {
+	t.Parallel()
+
+	const (
+		goroutines = 50
+		window     = time.Minute
+	)
+
+	var (
+		dedup   requestDeduplicator
+		skipped int64
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			var req fasthttp.Request
+
+			req.SetRequestURI("http://localhost/path")
+			req.Header.SetMethod(fasthttp.MethodPost)
+			req.SetBodyString("body")
+
+			if dedup.reserve(&req, window) > 0 {
+				atomic.AddInt64(&skipped, 1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if skipped != goroutines-1 {
+		t.Errorf("expected %d skipped requests, got %d", goroutines-1, skipped)
+	}
+
+	var other fasthttp.Request
+
+	other.SetRequestURI("http://localhost/path")
+	other.SetBodyString("other body")
+
+	if dedup.reserve(&other, window) > 0 {
+		t.Error("request with a different body shouldn't be skipped")
+	}
+}
+
+func TestRequestDeduplicatorWindowExpiry(t *testing.T) {
+	t.Parallel()
+
+	const window = 20 * time.Millisecond
+
+	var (
+		dedup requestDeduplicator
+		req   fasthttp.Request
+	)
+
+	req.SetRequestURI("http://localhost/")
+
+	if dedup.reserve(&req, window) > 0 {
+		t.Fatal("first request shouldn't be skipped")
+	}
+
+	if wait := dedup.reserve(&req, window); wait <= 0 || wait > window {
+		t.Fatalf("expected to wait at most %v for the window to pass, got %v", window, wait)
+	}
+
+	time.Sleep(2 * window)
+
+	if dedup.reserve(&req, window) > 0 {
+		t.Error("request should be sent again after the window expired")
+	}
+}
+
+func TestRequestDeduplicatorSweepKeepsLongerWindows(t *testing.T) {
+	t.Parallel()
+
+	const (
+		shortWindow = time.Millisecond
+		longWindow  = time.Minute
+	)
+
+	var (
+		dedup requestDeduplicator
+		req   fasthttp.Request
+	)
+
+	req.SetRequestURI("http://localhost/long")
+
+	if dedup.reserve(&req, longWindow) > 0 {
+		t.Fatal("first request shouldn't be skipped")
+	}
+
+	// a job with a short window must not evict the entries of a job with a long one
+	dedup.mutex.Lock()
+	dedup.sweep(time.Now().Add(2 * shortWindow))
+	dedup.mutex.Unlock()
+
+	if dedup.reserve(&req, longWindow) == 0 {
+		t.Error("entry was evicted before its window passed")
+	}
+}
@@ -0,0 +1,70 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Arriven/db1000n/src/utils/metrics"
+)
+
+// finalMetrics is dumped once on SIGTERM so that the stats aren't lost when the process is killed
+type finalMetrics struct {
+	ClientID     string                 `json:"client_id"`
+	RunDuration  string                 `json:"run_duration"`
+	FinalMetrics metrics.Stats          `json:"final_metrics"`
+	Targets      metrics.PerTargetStats `json:"targets"`
+	Jobs         []ActiveJobStatus      `json:"jobs"`
+}
+
+// writeFinalMetrics writes the dump to path or to stdout if path is empty
+func writeFinalMetrics(path string, dump finalMetrics) error {
+	var out io.Writer = os.Stdout
+
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		out = file
+	}
+
+	return json.NewEncoder(out).Encode(dump)
+}
+
+func (r *Runner) finalMetrics(tracker *metrics.StatsTracker, jobs []ActiveJobStatus, start time.Time) finalMetrics {
+	targets, totals := tracker.Snapshot(false)
+
+	return finalMetrics{
+		ClientID:     r.globalJobsCfg.ClientID,
+		RunDuration:  time.Since(start).String(),
+		FinalMetrics: totals,
+		Targets:      targets,
+		Jobs:         jobs,
+	}
+}
@@ -0,0 +1,152 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils"
+	"github.com/Arriven/db1000n/src/utils/metrics"
+)
+
+// ActiveJobStatus describes a running job instance
+type ActiveJobStatus struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Started     time.Time `json:"started"`
+	HealthScore float64   `json:"health_score"`
+}
+
+type activeJob struct {
+	cfg     config.Config
+	started time.Time
+	metrics *metrics.Accumulator
+}
+
+// ActiveJobs keeps track of running job instances. Safe for concurrent use.
+type ActiveJobs struct {
+	jobs utils.SyncMap[string, activeJob]
+}
+
+func (a *ActiveJobs) add(id string, cfg config.Config, acc *metrics.Accumulator) {
+	a.jobs.Store(id, activeJob{cfg: cfg, started: time.Now(), metrics: acc})
+}
+
+func (a *ActiveJobs) remove(id string) { a.jobs.Delete(id) }
+
+// Statuses returns all running job instances, oldest first
+func (a *ActiveJobs) Statuses() []ActiveJobStatus {
+	var res []ActiveJobStatus
+
+	a.jobs.Range(func(id string, job activeJob) bool {
+		res = append(res, ActiveJobStatus{ID: id, Name: job.cfg.Name, Type: job.cfg.Type, Started: job.started, HealthScore: job.metrics.HealthScore()})
+
+		return true
+	})
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Started.Before(res[j].Started) })
+
+	return res
+}
+
+// ServeHTTP responds with statuses of running job instances as json
+func (a *ActiveJobs) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(a.Statuses()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// watchHealth periodically checks health scores of all running job instances and runs cfg.OnUnhealthy of an instance
+// once every time its score drops below cfg.MinHealthScore
+func (a *ActiveJobs) watchHealth(ctx context.Context, globalConfig *GlobalConfig, logger *zap.Logger) {
+	const checkInterval = 10 * time.Second
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	unhealthy := make(map[string]bool)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// rebuilt on every check so that stopped instances are forgotten
+		stillUnhealthy := make(map[string]bool)
+
+		a.jobs.Range(func(id string, job activeJob) bool {
+			if job.cfg.MinHealthScore <= 0 {
+				return true
+			}
+
+			score := job.metrics.HealthScore()
+			if score >= job.cfg.MinHealthScore {
+				return true
+			}
+
+			stillUnhealthy[id] = true
+
+			if !unhealthy[id] {
+				logger.Warn("job health score is below the threshold",
+					zap.String("name", job.cfg.Name), zap.String("type", job.cfg.Type),
+					zap.Float64("health_score", score), zap.Float64("min_health_score", job.cfg.MinHealthScore))
+
+				go runOnUnhealthy(ctx, job.cfg, globalConfig, job.metrics, logger)
+			}
+
+			return true
+		})
+
+		unhealthy = stillUnhealthy
+	}
+}
+
+func runOnUnhealthy(ctx context.Context, cfg config.Config, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) {
+	if cfg.OnUnhealthy == nil {
+		return
+	}
+
+	job := Get(cfg.OnUnhealthy.Type)
+	if job == nil {
+		logger.Warn("unknown on_unhealthy job", zap.String("type", cfg.OnUnhealthy.Type))
+
+		return
+	}
+
+	if _, err := job(ctx, cfg.OnUnhealthy.Args, globalConfig, a.Clone(uuid.NewString()), logger); err != nil {
+		logger.Warn("error running on_unhealthy job", zap.String("type", cfg.OnUnhealthy.Type), zap.Error(err))
+	}
+}
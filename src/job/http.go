@@ -24,9 +24,12 @@ package job
 
 import (
 	"context"
+	"crypto/ed25519"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/valyala/fasthttp"
 	"go.uber.org/zap"
 
@@ -43,6 +46,45 @@ type httpJobConfig struct {
 	Dynamic bool           // parse template on every iteration. slower but allows more variability in generated traffic
 	Request map[string]any // See http.RequestConfig
 	Client  map[string]any // See http.ClientConfig
+
+	CorrelationID bool // add a unique id to every request via X-Correlation-ID, X-Request-ID and traceparent headers
+
+	Ed25519PrivateKey string // PKCS #8 PEM key (template) to sign every request with, see utils.Ed25519Sign
+	SignatureHeader   string // header to put the signature into, X-Ed25519-Signature by default
+
+	DeduplicateWindow time.Duration // skip requests identical to one sent within this window by any job, keep it short (50-200ms)
+
+	LogLatencyHeatmap bool          // periodically log a histogram of response times in 10ms buckets
+	ReportInterval    time.Duration // how often to log the latency heatmap, a minute by default
+}
+
+// requestSigner signs requests if the job has a signing key configured
+type requestSigner struct {
+	key    ed25519.PrivateKey
+	header string
+}
+
+func newRequestSigner(ctx context.Context, cfg *httpJobConfig, logger *zap.Logger) (*requestSigner, error) {
+	const defaultSignatureHeader = "X-Ed25519-Signature"
+
+	if cfg.Ed25519PrivateKey == "" {
+		return nil, nil
+	}
+
+	key, err := utils.ParseEd25519PrivateKey(templates.ParseAndExecute(logger, cfg.Ed25519PrivateKey, ctx))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing signing key: %w", err)
+	}
+
+	return &requestSigner{key: key, header: nonEmptyStringOrDefault(cfg.SignatureHeader, defaultSignatureHeader)}, nil
+}
+
+func (s *requestSigner) sign(req *fasthttp.Request) {
+	if s == nil {
+		return
+	}
+
+	req.Header.Set(s.header, utils.Ed25519Sign(s.key, string(req.Header.Method()), req.URI().String(), req.Body()))
 }
 
 // "http-request" in config
@@ -50,7 +92,7 @@ func singleRequestJob(ctx context.Context, args config.Args, globalConfig *Globa
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	_, clientConfig, requestTpl, err := getHTTPJobConfigs(ctx, args, *globalConfig, logger)
+	jobConfig, clientConfig, requestTpl, err := getHTTPJobConfigs(ctx, args, *globalConfig, logger)
 	if err != nil {
 		return nil, err
 	}
@@ -72,6 +114,18 @@ func singleRequestJob(ctx context.Context, args config.Args, globalConfig *Globa
 
 	http.InitRequest(requestConfig, req)
 
+	var correlationID string
+	if jobConfig.CorrelationID {
+		correlationID = setCorrelationID(req)
+	}
+
+	signer, err := newRequestSigner(ctx, jobConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	signer.sign(req)
+
 	if err = client.Do(req, resp); err != nil {
 		if a != nil {
 			a.Inc(target(req.URI()), metrics.RequestsAttemptedStat).Flush()
@@ -81,6 +135,7 @@ func singleRequestJob(ctx context.Context, args config.Args, globalConfig *Globa
 	}
 
 	requestSize, _ := req.WriteTo(nopWriter{})
+	responseSize, _ := resp.WriteTo(nopWriter{})
 
 	if a != nil {
 		tgt := target(req.URI())
@@ -89,6 +144,7 @@ func singleRequestJob(ctx context.Context, args config.Args, globalConfig *Globa
 			Inc(tgt, metrics.RequestsSentStat).
 			Inc(tgt, metrics.ResponsesReceivedStat).
 			Add(tgt, metrics.BytesSentStat, uint64(requestSize)).
+			Add(tgt, metrics.BytesReceivedStat, uint64(responseSize)).
 			Flush()
 	}
 
@@ -104,10 +160,24 @@ func singleRequestJob(ctx context.Context, args config.Args, globalConfig *Globa
 			"headers":     headers,
 			"cookies":     cookies,
 		},
-		"error": err,
+		"error":          err,
+		"correlation_id": correlationID,
 	}, nil
 }
 
+// setCorrelationID tags the request with a new unique id so that it can be found in the target's logs
+func setCorrelationID(req *fasthttp.Request) string {
+	id := uuid.NewString()
+	traceID := strings.ReplaceAll(id, "-", "")
+	spanID := strings.ReplaceAll(uuid.NewString(), "-", "")[:16]
+
+	req.Header.Set("X-Correlation-ID", id)
+	req.Header.Set("X-Request-ID", id)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+
+	return id
+}
+
 func headerLoaderFunc(headers map[string]string) func(key []byte, value []byte) {
 	return func(key []byte, value []byte) {
 		headers[string(key)] = string(value)
@@ -135,6 +205,8 @@ func cookieLoaderFunc(cookies map[string]string, logger *zap.Logger) func(key []
 
 // "http" or "http-flood" in config
 func fastHTTPJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
+	const defaultLatencyReportInterval = time.Minute
+
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -143,27 +215,64 @@ func fastHTTPJob(ctx context.Context, args config.Args, globalConfig *GlobalConf
 		return nil, err
 	}
 
-	backoffController := utils.BackoffController{BackoffConfig: utils.NonNilOrDefault(jobConfig.Backoff, globalConfig.Backoff)}
+	ctx = jobConfig.WatchActivity(ctx)
+	backoffController := utils.BackoffController{BackoffConfig: utils.Deref(jobConfig.Backoff, globalConfig.Backoff)}
 	client := http.NewClient(ctx, *clientConfig, logger)
 
+	signer, err := newRequestSigner(ctx, jobConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	var (
 		req  fasthttp.Request
 		resp fasthttp.Response
+
+		latencies  metrics.LatencyHistogram
+		lastReport = time.Now()
 	)
 
+	reportInterval := jobConfig.ReportInterval
+	if reportInterval <= 0 {
+		reportInterval = defaultLatencyReportInterval
+	}
+
 	if !jobConfig.Dynamic {
 		if err := buildHTTPRequest(ctx, logger, requestTpl, &req); err != nil {
 			return nil, fmt.Errorf("error executing request template: %w", err)
 		}
 	}
 
-	for jobConfig.Next(ctx) {
+	defer jobConfig.finishProgress()
+
+	for jobConfig.Next(ctx, a, logger) {
 		if jobConfig.Dynamic {
 			if err := buildHTTPRequest(ctx, logger, requestTpl, &req); err != nil {
 				return nil, fmt.Errorf("error executing request template: %w", err)
 			}
 		}
 
+		if jobConfig.CorrelationID {
+			setCorrelationID(&req)
+		}
+
+		signer.sign(&req)
+
+		if jobConfig.DeduplicateWindow > 0 {
+			if wait := requestDedup.reserve(&req, jobConfig.DeduplicateWindow); wait > 0 {
+				if a != nil {
+					a.Inc(target(req.URI()), metrics.DedupSkippedStat).Flush()
+				}
+
+				// identical requests would keep being skipped until the window passes, no point in spinning
+				utils.Sleep(ctx, wait)
+
+				continue
+			}
+		}
+
+		start := time.Now()
+
 		if err := client.Do(&req, &resp); err != nil {
 			logger.Debug("error sending request", zap.Error(err), zap.Any("args", args))
 
@@ -176,6 +285,17 @@ func fastHTTPJob(ctx context.Context, args config.Args, globalConfig *GlobalConf
 			continue
 		}
 
+		if jobConfig.LogLatencyHeatmap {
+			latencies.Observe(time.Since(start))
+
+			if time.Since(lastReport) >= reportInterval {
+				logger.Info("latency heatmap", zap.String("target", target(req.URI())), zap.Any("latency_heatmap", latencies.Heatmap()))
+				latencies.Reset()
+
+				lastReport = time.Now()
+			}
+		}
+
 		if a != nil {
 			requestSize, _ := req.WriteTo(nopWriter{})
 			responseSize, _ := resp.WriteTo(nopWriter{})
@@ -190,9 +310,10 @@ func fastHTTPJob(ctx context.Context, args config.Args, globalConfig *GlobalConf
 		}
 
 		backoffController.Reset()
+		jobConfig.Heartbeat()
 	}
 
-	return nil, nil
+	return nil, jobConfig.ActivityErr()
 }
 
 func buildHTTPRequest(ctx context.Context, logger *zap.Logger, requestTpl *templates.MapStruct, req *fasthttp.Request) error {
@@ -221,8 +342,7 @@ func getHTTPJobConfigs(ctx context.Context, args config.Args, global GlobalConfi
 		return nil, nil, nil, fmt.Errorf("error parsing client config: %w", err)
 	}
 
-	proxyCfg := utils.NonNilOrDefault(clientConfig.Proxy, global.GetProxyParams(logger, ctx))
-	clientConfig.Proxy = &proxyCfg
+	clientConfig.Proxy = utils.Ptr(utils.Deref(clientConfig.Proxy, global.GetProxyParams(logger, ctx)))
 
 	requestTpl, err = templates.ParseMapStruct(jobConfig.Request)
 	if err != nil {
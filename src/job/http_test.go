@@ -0,0 +1,71 @@
+package job
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/utils"
+)
+
+func TestRequestSigning(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !utils.Ed25519Verify(publicKey, r.Method, "http://"+r.Host+r.URL.RequestURI(), body, r.Header.Get("X-Ed25519-Signature")) {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	for _, tc := range []struct {
+		key      ed25519.PrivateKey
+		expected int
+	}{
+		{key: privateKey, expected: http.StatusOK},
+		{key: otherKey, expected: http.StatusUnauthorized},
+	} {
+		der, err := x509.MarshalPKCS8PrivateKey(tc.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		args := map[string]any{
+			"request":             map[string]any{"method": "POST", "path": server.URL + "/signed?a=b", "body": "payload"},
+			"ed25519_private_key": string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		}
+
+		data, err := singleRequestJob(context.Background(), args, &GlobalConfig{}, nil, zap.NewNop())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response, _ := data.(map[string]any)["response"].(map[string]any)
+		if status := response["status_code"]; status != tc.expected {
+			t.Errorf("expected status %d, got %v", tc.expected, status)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// Isolation modes for config.Config.IsolationMode
+const (
+	// IsolationNone runs job instances as regular goroutines, cheapest and the default
+	IsolationNone = "none"
+	// IsolationGoroutinePool limits the amount of concurrently running instances of the same job type to the number of CPUs.
+	// Prevents CPU-heavy jobs from starving everything else at the cost of queueing instances beyond the limit
+	IsolationGoroutinePool = "goroutine-pool"
+	// IsolationOSThread pins every job instance to its own OS thread. Gives the most predictable scheduling
+	// but each instance costs a full thread, only use it for a handful of instances
+	IsolationOSThread = "os-thread"
+)
+
+var (
+	poolsMutex sync.Mutex
+	jobPools   = make(map[string]chan struct{})
+)
+
+func jobPool(jobType string) chan struct{} {
+	poolsMutex.Lock()
+	defer poolsMutex.Unlock()
+
+	pool, ok := jobPools[jobType]
+	if !ok {
+		pool = make(chan struct{}, runtime.NumCPU())
+		jobPools[jobType] = pool
+	}
+
+	return pool
+}
+
+// isolate applies the isolation mode to the current goroutine, returned function has to be called once the job is done.
+// Returns false if the context got cancelled while waiting for a slot in the pool
+func isolate(ctx context.Context, mode, jobType string) (release func(), ok bool) {
+	switch mode {
+	case IsolationGoroutinePool:
+		pool := jobPool(jobType)
+
+		select {
+		case pool <- struct{}{}:
+			return func() { <-pool }, true
+		case <-ctx.Done():
+			return nil, false
+		}
+	case IsolationOSThread:
+		runtime.LockOSThread()
+
+		return runtime.UnlockOSThread, true
+	default:
+		return func() {}, true
+	}
+}
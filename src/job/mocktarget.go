@@ -0,0 +1,165 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils"
+	"github.com/Arriven/db1000n/src/utils/metrics"
+)
+
+//go:embed mocktarget/*.yaml
+var mockTargetProfiles embed.FS
+
+type mockTargetRoute struct {
+	Path        string
+	Status      int
+	Headers     map[string]string
+	Body        string
+	MinLatency  time.Duration
+	MaxLatency  time.Duration
+	ErrorRate   float64
+	ErrorStatus int
+}
+
+type mockTargetProfile struct {
+	Routes []mockTargetRoute
+}
+
+func loadMockTargetProfile(name string) (*mockTargetProfile, error) {
+	content, err := mockTargetProfiles.ReadFile("mocktarget/" + name + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+
+	var raw map[string]any
+	if err := utils.Unmarshal(content, &raw, "yaml"); err != nil {
+		return nil, err
+	}
+
+	var profile mockTargetProfile
+	if err := utils.Decode(raw, &profile); err != nil {
+		return nil, err
+	}
+
+	return &profile, nil
+}
+
+// "mock-target" in config
+func mockTargetJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (
+	data any, err error, //nolint:unparam // data is here to match Job
+) {
+	const defaultAddress = "127.0.0.1:8080"
+
+	var jobConfig struct {
+		Profile string
+		Address string
+	}
+
+	if err := utils.Decode(args, &jobConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	profile, err := loadMockTargetProfile(jobConfig.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{
+		Addr:              nonEmptyStringOrDefault(jobConfig.Address, defaultAddress),
+		Handler:           mockTargetHandler(jobConfig.Profile, profile, a),
+		ReadHeaderTimeout: time.Minute,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		server.Close()
+	}()
+
+	logger.Info("serving mock target", zap.String("profile", jobConfig.Profile), zap.String("address", server.Addr))
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return nil, err
+	}
+
+	return nil, nil
+}
+
+func mockTargetHandler(profileName string, profile *mockTargetProfile, a *metrics.Accumulator) http.Handler {
+	var mutex sync.Mutex // accumulator is not concurrency-safe
+
+	mux := http.NewServeMux()
+
+	for i := range profile.Routes {
+		route := profile.Routes[i]
+
+		mux.HandleFunc(route.Path, func(w http.ResponseWriter, r *http.Request) {
+			if a != nil {
+				mutex.Lock()
+				a.Inc("mock://"+profileName+route.Path, metrics.RequestsAttemptedStat).Flush()
+				mutex.Unlock()
+			}
+
+			latency := route.MinLatency
+			if route.MaxLatency > route.MinLatency {
+				latency += time.Duration(rand.Int63n(int64(route.MaxLatency - route.MinLatency))) //nolint:gosec // Cryptographically secure random not required
+			}
+
+			if !utils.Sleep(r.Context(), latency) {
+				return
+			}
+
+			for k, v := range route.Headers {
+				w.Header().Set(k, v)
+			}
+
+			if route.ErrorRate > 0 && rand.Float64() < route.ErrorRate { //nolint:gosec // Cryptographically secure random not required
+				status := route.ErrorStatus
+				if status == 0 {
+					status = http.StatusServiceUnavailable
+				}
+
+				w.WriteHeader(status)
+
+				return
+			}
+
+			w.WriteHeader(route.Status)
+			_, _ = w.Write([]byte(route.Body))
+		})
+	}
+
+	return mux
+}
@@ -0,0 +1,188 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/Arriven/db1000n/src/core/http"
+	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils"
+	"github.com/Arriven/db1000n/src/utils/metrics"
+	"github.com/Arriven/db1000n/src/utils/templates"
+)
+
+// oauthToken holds the latest access token, templates render it via String so they always get the fresh one
+type oauthToken struct {
+	mutex sync.RWMutex
+	value string
+}
+
+func (t *oauthToken) String() string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.value
+}
+
+func (t *oauthToken) set(value string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.value = value
+}
+
+// "oauth2-token" in config
+// Fetches a token with the client credentials flow and runs the inner job with the token available to its templates
+// as {{ .Value (ctx_key "oauth_token") }} (or whatever context_key is set to). The token is refreshed in background.
+func oauth2TokenJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
+	const (
+		defaultContextKey          = "oauth_token"
+		defaultRefreshBeforeExpiry = time.Minute
+	)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var jobConfig struct {
+		TokenURL            string
+		ClientID            string
+		ClientSecret        string
+		Scopes              []string
+		ContextKey          string
+		RefreshBeforeExpiry time.Duration
+		Client              map[string]any // See http.ClientConfig
+		Job                 config.Config
+	}
+
+	if err := utils.Decode(args, &jobConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	var clientConfig http.ClientConfig
+	if err := utils.Decode(templates.ParseAndExecuteMapStruct(logger, jobConfig.Client, ctx), &clientConfig); err != nil {
+		return nil, fmt.Errorf("error parsing client config: %w", err)
+	}
+
+	clientConfig.Proxy = utils.Ptr(utils.Deref(clientConfig.Proxy, globalConfig.GetProxyParams(logger, ctx)))
+
+	if jobConfig.RefreshBeforeExpiry <= 0 {
+		jobConfig.RefreshBeforeExpiry = defaultRefreshBeforeExpiry
+	}
+
+	job := Get(jobConfig.Job.Type)
+	if job == nil {
+		return nil, fmt.Errorf("unknown job %q", jobConfig.Job.Type)
+	}
+
+	credentials := clientcredentials.Config{
+		ClientID:     templates.ParseAndExecute(logger, jobConfig.ClientID, ctx),
+		ClientSecret: templates.ParseAndExecute(logger, jobConfig.ClientSecret, ctx),
+		TokenURL:     jobConfig.TokenURL,
+		Scopes:       jobConfig.Scopes,
+	}
+
+	// token requests go through the same proxy and timeouts as the http jobs
+	tokenCtx := context.WithValue(ctx, oauth2.HTTPClient, http.NewStdClient(clientConfig, logger))
+
+	initial, err := credentials.Token(tokenCtx)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching oauth2 token: %w", err)
+	}
+
+	token := &oauthToken{value: initial.AccessToken}
+
+	go refreshOAuth2Token(tokenCtx, &credentials, token, initial.Expiry, jobConfig.RefreshBeforeExpiry, logger)
+
+	ctx = context.WithValue(ctx, templates.ContextKey(nonEmptyStringOrDefault(jobConfig.ContextKey, defaultContextKey)), token)
+
+	return job(ctx, jobConfig.Job.Args, globalConfig, a, logger)
+}
+
+// refreshOAuth2Token re-fetches the token refreshBeforeExpiry before it expires until ctx is done, tokens without expiry are never refreshed
+func refreshOAuth2Token(ctx context.Context, credentials *clientcredentials.Config, token *oauthToken, expiry time.Time,
+	refreshBeforeExpiry time.Duration, logger *zap.Logger,
+) {
+	const retryInterval = 10 * time.Second
+
+	if expiry.IsZero() {
+		return
+	}
+
+	for refreshAt := oauth2RefreshAt(time.Now(), expiry, refreshBeforeExpiry, retryInterval); ; {
+		if !utils.Sleep(ctx, time.Until(refreshAt)) {
+			return
+		}
+
+		fresh, err := credentials.Token(ctx)
+		if err != nil {
+			logger.Warn("error refreshing oauth2 token", zap.String("url", credentials.TokenURL), zap.Error(err))
+
+			refreshAt = oauth2RetryAt(time.Now(), expiry, retryInterval)
+
+			continue
+		}
+
+		token.set(fresh.AccessToken)
+
+		if expiry = fresh.Expiry; expiry.IsZero() {
+			return
+		}
+
+		refreshAt = oauth2RefreshAt(time.Now(), expiry, refreshBeforeExpiry, retryInterval)
+	}
+}
+
+// oauth2RefreshAt returns when to refresh a token: refreshBeforeExpiry before it expires, but not before half of its remaining
+// lifetime passed so that tokens living shorter than refreshBeforeExpiry don't get refreshed in a tight loop
+func oauth2RefreshAt(now, expiry time.Time, refreshBeforeExpiry, retryInterval time.Duration) time.Time {
+	if !expiry.After(now) {
+		return now.Add(retryInterval)
+	}
+
+	const lifetimeFraction = 2
+
+	refreshAt := expiry.Add(-refreshBeforeExpiry)
+	if halfLife := now.Add(expiry.Sub(now) / lifetimeFraction); refreshAt.Before(halfLife) {
+		return halfLife
+	}
+
+	return refreshAt
+}
+
+// oauth2RetryAt returns when to retry a failed refresh: after retryInterval, but no later than the current token expires
+func oauth2RetryAt(now, expiry time.Time, retryInterval time.Duration) time.Time {
+	retryAt := now.Add(retryInterval)
+	if expiry.After(now) && retryAt.After(expiry) {
+		return expiry
+	}
+
+	return retryAt
+}
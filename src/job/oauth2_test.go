@@ -0,0 +1,170 @@
+package job
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// connectProxy is a minimal http proxy that only supports tunneling and counts tunnels
+type connectProxy struct {
+	tunnels int32
+}
+
+func (p *connectProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodConnect {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	upstream, err := net.Dial("tcp", r.Host)
+	if err != nil {
+		w.WriteHeader(http.StatusBadGateway)
+
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+
+		return
+	}
+
+	atomic.AddInt32(&p.tunnels, 1)
+
+	_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+
+	go func() {
+		defer upstream.Close()
+
+		_, _ = io.Copy(upstream, conn)
+	}()
+
+	go func() {
+		defer conn.Close()
+
+		_, _ = io.Copy(conn, upstream)
+	}()
+}
+
+func TestOAuth2TokenJobUsesProxy(t *testing.T) {
+	t.Parallel()
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "test-token", "token_type": "bearer", "expires_in": 3600}`))
+	}))
+	defer tokenServer.Close()
+
+	proxy := &connectProxy{}
+
+	proxyServer := httptest.NewServer(proxy)
+	defer proxyServer.Close()
+
+	args := map[string]any{
+		"token_url":     tokenServer.URL,
+		"client_id":     "id",
+		"client_secret": "secret",
+		"client":        map[string]any{"timeout": "5s"},
+		"job":           map[string]any{"type": "set-value", "args": map[string]any{"value": `{{ .Value (ctx_key "oauth_token") }}`}},
+	}
+
+	data, err := oauth2TokenJob(context.Background(), args, &GlobalConfig{ProxyURLs: proxyServer.URL}, nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data != "test-token" {
+		t.Errorf("expected the inner job to see the token, got %v", data)
+	}
+
+	if tunnels := atomic.LoadInt32(&proxy.tunnels); tunnels != 1 {
+		t.Errorf("expected the token request to go through the proxy, got %d tunnels", tunnels)
+	}
+}
+
+func TestOAuth2ShortLivedTokenRefresh(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token": "short-lived", "token_type": "bearer", "expires_in": 1}`))
+	}))
+	defer tokenServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1200*time.Millisecond)
+	defer cancel()
+
+	credentials := clientcredentials.Config{ClientID: "id", ClientSecret: "secret", TokenURL: tokenServer.URL}
+
+	// the token lives for a second which is shorter than the default refresh_before_expiry
+	refreshOAuth2Token(ctx, &credentials, &oauthToken{}, time.Now().Add(time.Second), time.Minute, zap.NewNop())
+
+	if n := atomic.LoadInt32(&requests); n < 1 || n > 3 {
+		t.Errorf("expected the token to be refreshed about twice a second, got %d requests", n)
+	}
+}
+
+func TestOAuth2RefreshAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	if refreshAt := oauth2RefreshAt(now, now.Add(time.Hour), time.Minute, 10*time.Second); !refreshAt.Equal(now.Add(59 * time.Minute)) {
+		t.Errorf("expected refresh a minute before expiry, got %v", refreshAt.Sub(now))
+	}
+
+	if refreshAt := oauth2RefreshAt(now, now.Add(30*time.Second), time.Minute, 10*time.Second); !refreshAt.Equal(now.Add(15 * time.Second)) {
+		t.Errorf("expected refresh after half of the lifetime of a short-lived token, got %v", refreshAt.Sub(now))
+	}
+
+	if refreshAt := oauth2RefreshAt(now, now.Add(-time.Second), time.Minute, 10*time.Second); !refreshAt.Equal(now.Add(10 * time.Second)) {
+		t.Errorf("expected refresh after the retry interval for an expired token, got %v", refreshAt.Sub(now))
+	}
+}
+
+func TestOAuth2RetryAt(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	if retryAt := oauth2RetryAt(now, now.Add(time.Minute), 10*time.Second); !retryAt.Equal(now.Add(10 * time.Second)) {
+		t.Errorf("expected retry after the retry interval, got %v", retryAt.Sub(now))
+	}
+
+	if retryAt := oauth2RetryAt(now, now.Add(time.Second), 10*time.Second); !retryAt.Equal(now.Add(time.Second)) {
+		t.Errorf("expected retry no later than expiry, got %v", retryAt.Sub(now))
+	}
+
+	if retryAt := oauth2RetryAt(now, now.Add(-time.Second), 10*time.Second); !retryAt.Equal(now.Add(10 * time.Second)) {
+		t.Errorf("expected retry after the retry interval once the token expired, got %v", retryAt.Sub(now))
+	}
+}
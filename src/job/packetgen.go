@@ -54,9 +54,12 @@ func packetgenJob(ctx context.Context, args config.Args, globalConfig *GlobalCon
 		return nil, fmt.Errorf("error parsing job config: %w", err)
 	}
 
-	backoffController := utils.BackoffController{BackoffConfig: utils.NonNilOrDefault(jobConfig.Backoff, globalConfig.Backoff)}
+	ctx = jobConfig.WatchActivity(ctx)
+	backoffController := utils.BackoffController{BackoffConfig: utils.Deref(jobConfig.Backoff, globalConfig.Backoff)}
 
-	for jobConfig.Next(ctx) {
+	defer jobConfig.finishProgress()
+
+	for jobConfig.Next(ctx, a, logger) {
 		if err := sendPacket(ctx, logger, jobConfig, a); err != nil {
 			logger.Debug("error sending packet", zap.Error(err), zap.Any("args", args))
 			utils.Sleep(ctx, backoffController.Increment().GetTimeout())
@@ -65,7 +68,7 @@ func packetgenJob(ctx context.Context, args config.Args, globalConfig *GlobalCon
 		}
 	}
 
-	return nil, nil
+	return nil, jobConfig.ActivityErr()
 }
 
 func sendPacket(ctx context.Context, logger *zap.Logger, jobConfig *packetgenJobConfig, a *metrics.Accumulator) error {
@@ -85,7 +88,7 @@ func sendPacket(ctx context.Context, logger *zap.Logger, jobConfig *packetgenJob
 		return err
 	}
 
-	for jobConfig.Next(ctx) {
+	for jobConfig.Next(ctx, a, logger) {
 		packet, err := packetSrc(ctx, logger)
 		if err != nil {
 			return err
@@ -108,6 +111,8 @@ func sendPacket(ctx context.Context, logger *zap.Logger, jobConfig *packetgenJob
 				Add(tgt, metrics.BytesSentStat, uint64(n)).
 				Flush()
 		}
+
+		jobConfig.Heartbeat()
 	}
 
 	return nil
@@ -271,8 +276,7 @@ func parsePacketgenArgs(ctx context.Context, args config.Args, globalConfig *Glo
 		return nil, err
 	}
 
-	proxyCfg := utils.NonNilOrDefault(jobConfig.Connection.Proxy, globalConfig.GetProxyParams(logger, ctx))
-	jobConfig.Connection.Proxy = &proxyCfg
+	jobConfig.Connection.Proxy = utils.Ptr(utils.Deref(jobConfig.Connection.Proxy, globalConfig.GetProxyParams(logger, ctx)))
 
 	return &packetgenJobConfig{
 		BasicJobConfig: jobConfig.BasicJobConfig,
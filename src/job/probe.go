@@ -0,0 +1,152 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils"
+)
+
+// jobConfigFlag registers a flag (with environment variable fallback) that holds a single job config in yaml or json format
+func jobConfigFlag(target **config.Config, name, envName, usage string) {
+	parse := func(raw string) error {
+		if raw == "" {
+			*target = nil
+
+			return nil
+		}
+
+		var cfg config.Config
+		if err := utils.Unmarshal([]byte(raw), &cfg, "yaml"); err != nil {
+			return fmt.Errorf("error parsing job config: %w", err)
+		}
+
+		*target = &cfg
+
+		return nil
+	}
+
+	// fail the same way flag.Parse does on an invalid value rather than silently running without the probe
+	if err := parse(utils.GetEnvStringDefault(envName, "")); err != nil {
+		const invalidUsageExitCode = 2
+
+		fmt.Fprintf(flag.CommandLine.Output(), "invalid value of %s environment variable: %v\n", envName, err)
+		os.Exit(invalidUsageExitCode)
+	}
+
+	flag.Func(name, usage, parse)
+}
+
+// runProbe executes the probe job once, probes don't report any metrics
+func runProbe(ctx context.Context, probe *config.Config, globalConfig *GlobalConfig, logger *zap.Logger) error {
+	job := Get(probe.Type)
+	if job == nil {
+		return fmt.Errorf("unknown job %q", probe.Type)
+	}
+
+	_, err := job(ctx, probe.Args, globalConfig, nil, logger)
+
+	return err
+}
+
+// runStartupProbe blocks until the startup probe succeeds or runs out of attempts
+func (r *Runner) runStartupProbe(ctx context.Context, logger *zap.Logger) error {
+	const (
+		attempts = 5
+		interval = 10 * time.Second
+	)
+
+	probe := r.globalJobsCfg.StartupProbe
+	if probe == nil {
+		return nil
+	}
+
+	var err error
+
+	for i := 0; i < attempts; i++ {
+		if err = runProbe(ctx, probe, r.globalJobsCfg, logger); err == nil {
+			return nil
+		}
+
+		logger.Warn("startup probe failed", zap.Int("attempt", i+1), zap.Error(err))
+
+		if i+1 < attempts && !utils.Sleep(ctx, interval) {
+			return ctx.Err()
+		}
+	}
+
+	return fmt.Errorf("startup probe failed after %d attempts: %w", attempts, err)
+}
+
+// readinessProbePassed runs the readiness probe if it's configured and logs the failure
+func (r *Runner) readinessProbePassed(ctx context.Context, logger *zap.Logger) bool {
+	probe := r.globalJobsCfg.ReadinessProbe
+	if probe == nil {
+		return true
+	}
+
+	if err := runProbe(ctx, probe, r.globalJobsCfg, logger); err != nil {
+		logger.Warn("new config received but readiness probe failed, keeping the current one", zap.Error(err))
+
+		return false
+	}
+
+	return true
+}
+
+// watchLiveness periodically runs the liveness probe and reports an error once it fails enough times in a row
+func (r *Runner) watchLiveness(ctx context.Context, logger *zap.Logger, result chan<- error) {
+	probe := r.globalJobsCfg.LivenessProbe
+	if probe == nil || r.globalJobsCfg.LivenessInterval <= 0 {
+		return
+	}
+
+	var failures int
+
+	for utils.Sleep(ctx, r.globalJobsCfg.LivenessInterval) {
+		err := runProbe(ctx, probe, r.globalJobsCfg, logger)
+		if err == nil {
+			failures = 0
+
+			continue
+		}
+
+		failures++
+
+		logger.Warn("liveness probe failed", zap.Int("consecutive_failures", failures), zap.Error(err))
+
+		if failures >= r.globalJobsCfg.LivenessThreshold {
+			result <- fmt.Errorf("liveness probe failed %d times in a row: %w", failures, err)
+
+			return
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+
+	"github.com/Arriven/db1000n/src/utils/metrics"
+)
+
+// updateProgress renders loop completion in the terminal, only loops limited by count or max requests have a known total
+func (c *BasicJobConfig) updateProgress(a *metrics.Accumulator) {
+	if !c.progressChecked {
+		c.progressChecked = true
+
+		if c.progress = newProgressBar(c.Count, c.MaxRequests); c.progress != nil {
+			c.progressID = progressLines.add()
+		}
+	}
+
+	if c.progress == nil {
+		return
+	}
+
+	if c.MaxRequests > 0 {
+		_ = c.progress.Set64(int64(a.Requests()))
+	} else {
+		_ = c.progress.Add(1)
+	}
+
+	progressLines.update(c.progressID, progressLine(c.progress))
+}
+
+// finishProgress leaves the final state of the bar above the ones that are still running.
+// Next calls it when the loop ends, loops also defer it to clean up when they return early.
+func (c *BasicJobConfig) finishProgress() {
+	if c.progress == nil {
+		return
+	}
+
+	progressLines.remove(c.progressID, progressLine(c.progress))
+	c.progress = nil
+}
+
+// newProgressBar returns a bar that only tracks the state, progressLines does the actual rendering
+func newProgressBar(count int, maxRequests int64) *progressbar.ProgressBar {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+
+	switch {
+	case maxRequests > 0:
+		return progressbar.NewOptions64(maxRequests, progressbar.OptionSetWriter(io.Discard), progressbar.OptionSetDescription("requests"))
+	case count > 0:
+		return progressbar.NewOptions(count, progressbar.OptionSetWriter(io.Discard), progressbar.OptionSetDescription("iterations"))
+	default:
+		return nil
+	}
+}
+
+func progressLine(bar *progressbar.ProgressBar) string {
+	return strings.TrimLeft(bar.String(), "\r")
+}
+
+// progressLines is shared by all loops so that concurrent bars don't overwrite each other
+var progressLines = newProgressRenderer(os.Stderr, 100*time.Millisecond)
+
+// progressRenderer draws every active progress bar on its own line at the bottom of the terminal. Concurrency-safe.
+type progressRenderer struct {
+	mutex    sync.Mutex
+	out      io.Writer
+	throttle time.Duration
+
+	nextID    uint64
+	order     []uint64
+	lines     map[uint64]string
+	finished  []string // lines of removed bars that have to be printed once above the active ones
+	drawn     int      // how many lines the last redraw took
+	lastDrawn time.Time
+}
+
+func newProgressRenderer(out io.Writer, throttle time.Duration) *progressRenderer {
+	return &progressRenderer{out: out, throttle: throttle, lines: make(map[uint64]string)}
+}
+
+func (r *progressRenderer) add() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.nextID++
+	r.order = append(r.order, r.nextID)
+	r.lines[r.nextID] = ""
+	r.redraw()
+
+	return r.nextID
+}
+
+func (r *progressRenderer) update(id uint64, line string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.lines[id] = line
+
+	if time.Since(r.lastDrawn) >= r.throttle {
+		r.redraw()
+	}
+}
+
+func (r *progressRenderer) remove(id uint64, final string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.order {
+		if r.order[i] == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+
+			break
+		}
+	}
+
+	delete(r.lines, id)
+	r.finished = append(r.finished, final)
+	r.redraw()
+}
+
+// Write prints p in place of the bars and draws them again below it, so that other output sharing the terminal
+// (like logs) doesn't get mixed with the bars
+func (r *progressRenderer) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.drawn == 0 {
+		return r.out.Write(p)
+	}
+
+	// move the cursor to the first bar and clear everything below it
+	if _, err := fmt.Fprintf(r.out, "\033[%dA\033[J", r.drawn); err != nil {
+		return 0, err
+	}
+
+	n, err := r.out.Write(p)
+
+	r.drawn = 0
+	r.redraw()
+
+	return n, err
+}
+
+// progressAwareSink is a zap.Sink that writes through progressLines
+type progressAwareSink struct{ *progressRenderer }
+
+func (progressAwareSink) Sync() error  { return nil }
+func (progressAwareSink) Close() error { return nil }
+
+// ProgressAwareStderr returns a sink for logs written to stderr that keeps them from being overwritten by progress bars
+func ProgressAwareStderr() zap.Sink {
+	return progressAwareSink{progressLines}
+}
+
+// redraw has to be called with the mutex held
+func (r *progressRenderer) redraw() {
+	const clearLine = "\r\033[2K"
+
+	var b strings.Builder
+
+	// move the cursor back to the first line of the previous redraw
+	if r.drawn > 0 {
+		fmt.Fprintf(&b, "\033[%dA", r.drawn)
+	}
+
+	printed := 0
+
+	for _, line := range r.finished {
+		b.WriteString(clearLine + line + "\n")
+	}
+
+	for _, id := range r.order {
+		b.WriteString(clearLine + r.lines[id] + "\n")
+
+		printed++
+	}
+
+	// clean up lines that were taken by removed bars
+	if extra := r.drawn - len(r.finished) - printed; extra > 0 {
+		b.WriteString(strings.Repeat(clearLine+"\n", extra))
+		fmt.Fprintf(&b, "\033[%dA", extra)
+	}
+
+	_, _ = io.WriteString(r.out, b.String())
+
+	r.finished, r.drawn, r.lastDrawn = nil, printed, time.Now()
+}
@@ -0,0 +1,74 @@
+package job
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestProgressRendererDrawsLinePerBar(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	r := newProgressRenderer(&out, 0)
+	first, second := r.add(), r.add()
+
+	r.update(first, "first 10%")
+	out.Reset()
+	r.update(second, "second 20%")
+
+	if expected := "\033[2A\r\033[2Kfirst 10%\n\r\033[2Ksecond 20%\n"; out.String() != expected {
+		t.Errorf("expected both bars to be redrawn on their own lines, got %q", out.String())
+	}
+
+	out.Reset()
+	r.remove(first, "first 100%")
+
+	if expected := "\033[2A\r\033[2Kfirst 100%\n\r\033[2Ksecond 20%\n"; out.String() != expected {
+		t.Errorf("expected the finished bar to stay above the active one, got %q", out.String())
+	}
+
+	out.Reset()
+	r.remove(second, "second 100%")
+	r.add()
+
+	if expected := "\033[1A\r\033[2Ksecond 100%\n\r\033[2K\n"; out.String() != expected {
+		t.Errorf("expected a new bar to take the next line, got %q", out.String())
+	}
+}
+
+func TestProgressRendererThrottlesUpdates(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	r := newProgressRenderer(&out, time.Hour)
+	id := r.add()
+
+	out.Reset()
+	r.update(id, "10%")
+
+	if out.Len() != 0 {
+		t.Errorf("expected the update to be throttled, got %q", out.String())
+	}
+}
+
+func TestProgressRendererKeepsBarsBelowWrittenLines(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+
+	r := newProgressRenderer(&out, 0)
+	r.update(r.add(), "bar 10%")
+
+	out.Reset()
+
+	if _, err := r.Write([]byte("log line\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "\033[1A\033[Jlog line\n\r\033[2Kbar 10%\n"; out.String() != expected {
+		t.Errorf("expected the line to replace the bar and the bar to be redrawn below, got %q", out.String())
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils"
 	"github.com/Arriven/db1000n/src/utils/metrics"
 )
 
@@ -70,28 +71,23 @@ func parseRawNetJobArgs(globalConfig *GlobalConfig, args config.Args, protocol s
 		return nil, fmt.Errorf("error decoding rawnet job config: %w", err)
 	}
 
-	packetgenArgs := make(map[string]any)
-	for k, v := range args {
-		packetgenArgs[k] = v
-	}
-
-	packetgenArgs["connection"] = map[string]any{
-		"type": "net",
-		"args": map[string]any{
-			"protocol":   protocol,
-			"address":    jobConfig.Address,
-			"timeout":    jobConfig.Timeout,
-			"proxy_urls": jobConfig.ProxyURLs,
+	return utils.Merge(args, map[string]any{
+		"connection": map[string]any{
+			"type": "net",
+			"args": map[string]any{
+				"protocol":   protocol,
+				"address":    jobConfig.Address,
+				"timeout":    jobConfig.Timeout,
+				"proxy_urls": jobConfig.ProxyURLs,
+			},
 		},
-	}
-	packetgenArgs["packet"] = map[string]any{
-		"payload": map[string]any{
-			"type": "raw",
-			"data": map[string]any{
-				"payload": jobConfig.Body,
+		"packet": map[string]any{
+			"payload": map[string]any{
+				"type": "raw",
+				"data": map[string]any{
+					"payload": jobConfig.Body,
+				},
 			},
 		},
-	}
-
-	return packetgenArgs, nil
+	}), nil
 }
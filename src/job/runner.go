@@ -25,10 +25,18 @@ package job
 import (
 	"bytes"
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"math"
 	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
 	"runtime"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -47,10 +55,21 @@ type ConfigOptions struct {
 	BackupConfig   string        // Raw backup config
 	Format         string        // json or yaml
 	RefreshTimeout time.Duration // How often to refresh config
+	HistorySize    int           // How many finished job instances to keep in history
+
+	SampleProfileInterval time.Duration // How often to sample goroutine stacks, zero disables sampling
+	SampleProfilePath     string        // Where to append goroutine stack samples
+	FinalMetricsPath      string        // Where to write the metrics dump on SIGTERM, stdout if empty
+	ShutdownTimeout       time.Duration // How long to wait for jobs to stop on SIGTERM
 }
 
 var DefaultConfigPathCSV = ""
 
+const (
+	defaultHistorySize     = 100
+	defaultShutdownTimeout = 10 * time.Second
+)
+
 // NewConfigOptionsWithFlags returns ConfigOptions initialized with command line flags.
 func NewConfigOptionsWithFlags() *ConfigOptions {
 	var res ConfigOptions
@@ -62,6 +81,16 @@ func NewConfigOptionsWithFlags() *ConfigOptions {
 	flag.StringVar(&res.Format, "format", utils.GetEnvStringDefault("CONFIG_FORMAT", "yaml"), "config format")
 	flag.DurationVar(&res.RefreshTimeout, "refresh-interval", utils.GetEnvDurationDefault("REFRESH_INTERVAL", time.Minute),
 		"refresh timeout for updating the config")
+	flag.IntVar(&res.HistorySize, "history-size", utils.GetEnvIntDefault("HISTORY_SIZE", defaultHistorySize),
+		"how many finished job instances to keep for introspection")
+	flag.DurationVar(&res.SampleProfileInterval, "sample-profile-interval", utils.GetEnvDurationDefault("SAMPLE_PROFILE_INTERVAL", 0),
+		"how often to append a summary of goroutine stacks to sample-profile-path, disabled by default")
+	flag.StringVar(&res.SampleProfilePath, "sample-profile-path", utils.GetEnvStringDefault("SAMPLE_PROFILE_PATH", "stacks.jsonl"),
+		"file to write goroutine stack samples to (only applies if sample-profile-interval is set)")
+	flag.StringVar(&res.FinalMetricsPath, "final-metrics-path", utils.GetEnvStringDefault("FINAL_METRICS_PATH", ""),
+		"file to write a json metrics dump to on SIGTERM, stdout by default")
+	flag.DurationVar(&res.ShutdownTimeout, "shutdown-timeout", utils.GetEnvDurationDefault("SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
+		"how long to wait for running jobs to stop on SIGTERM before giving up on them")
 
 	return &res
 }
@@ -71,6 +100,22 @@ type Runner struct {
 	cfgOptions    *ConfigOptions
 	globalJobsCfg *GlobalConfig
 	reporter      metrics.Reporter
+	history       *utils.JobHistory
+	active        ActiveJobs
+	resources     utils.ResourceUsage
+
+	jobs     sync.WaitGroup
+	stop     chan struct{}
+	reload   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+
+	hooksMutex    sync.Mutex
+	shutdownHooks []func()
+
+	signals chan os.Signal // SIGTERM is subscribed to on Run if not set
+
+	aggregated *metrics.AggregatedReporter // stats of named jobs of the current config, only accessed by Run
 }
 
 // NewRunner according to the config
@@ -79,31 +124,225 @@ func NewRunner(cfgOptions *ConfigOptions, globalJobsCfg *GlobalConfig, reporter
 		cfgOptions:    cfgOptions,
 		globalJobsCfg: globalJobsCfg,
 		reporter:      reporter,
+		history:       utils.NewJobHistory(cfgOptions.HistorySize),
+		stop:          make(chan struct{}),
+		reload:        make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+}
+
+// OnShutdown registers a hook that is called after all jobs are stopped by Shutdown
+func (r *Runner) OnShutdown(hook func()) {
+	r.hooksMutex.Lock()
+	defer r.hooksMutex.Unlock()
+
+	r.shutdownHooks = append(r.shutdownHooks, hook)
+}
+
+// Shutdown stops all jobs, flushes metrics and calls shutdown hooks.
+// Returns an error if Run doesn't return within the timeout.
+func (r *Runner) Shutdown(timeout time.Duration) error {
+	r.stopOnce.Do(func() { close(r.stop) })
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("runner didn't stop within %v", timeout)
+	}
+}
+
+// Reload makes the runner refresh its config right away instead of waiting for the refresh timeout
+func (r *Runner) Reload() error {
+	select {
+	case <-r.done:
+		return errors.New("runner is stopped")
+	default:
+	}
+
+	select {
+	case r.reload <- struct{}{}:
+	default: // a reload is already pending
+	}
+
+	return nil
+}
+
+// waitJobs waits for job instances to stop, returns false if some of them are still running after the shutdown timeout
+func (r *Runner) waitJobs() bool {
+	timeout := r.cfgOptions.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	stopped := make(chan struct{})
+
+	go func() {
+		r.jobs.Wait()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+func (r *Runner) runShutdownHooks() {
+	r.hooksMutex.Lock()
+	defer r.hooksMutex.Unlock()
+
+	for _, hook := range r.shutdownHooks {
+		hook()
+	}
+}
+
+// History of recently finished job instances
+func (r *Runner) History() *utils.JobHistory {
+	return r.history
+}
+
+// watchConfigMap reloads the config as soon as the ConfigMap changes instead of waiting for the refresh timer
+func (r *Runner) watchConfigMap(ctx context.Context, path string, logger *zap.Logger) {
+	err := config.WatchConfigMap(ctx, path, func() {
+		logger.Info("configmap changed", zap.String("path", path))
+
+		if err := r.Reload(); err != nil {
+			logger.Debug("failed to reload config", zap.Error(err))
+		}
+	})
+	if err != nil {
+		logger.Warn("can't watch configmap, falling back to polling", zap.String("path", path), zap.Error(err))
 	}
 }
 
-// Run the runner and block until Stop() is called
-func (r *Runner) Run(ctx context.Context, logger *zap.Logger) {
+// ActiveJobs returns currently running job instances
+func (r *Runner) ActiveJobs() *ActiveJobs {
+	return &r.active
+}
+
+// Run the runner and block until the context is cancelled or Shutdown() is called.
+// Returns an error if the startup or liveness probe fails, jobs are stopped and shutdown hooks are called by then.
+func (r *Runner) Run(ctx context.Context, logger *zap.Logger) error {
+	defer close(r.done)
+
+	start := time.Now()
+
+	if r.signals == nil {
+		r.signals = make(chan os.Signal, 1)
+		signal.Notify(r.signals, syscall.SIGTERM)
+
+		defer signal.Stop(r.signals)
+	}
+
 	ctx = context.WithValue(ctx, templates.ContextKey("global"), r.globalJobsCfg)
 	lastKnownConfig := &config.RawMultiConfig{}
+
+	if r.globalJobsCfg.DebugTemplates {
+		if err := templates.EnableDebugRecording(r.globalJobsCfg.DebugTemplatesPath); err != nil {
+			logger.Warn("failed to enable template debug recording", zap.Error(err))
+		}
+	}
+
+	if r.globalJobsCfg.JobTracePath != "" {
+		if err := EnableTracing(r.globalJobsCfg.JobTracePath); err != nil {
+			logger.Warn("failed to enable job tracing", zap.Error(err))
+		}
+	}
+
+	if r.cfgOptions.SampleProfileInterval > 0 {
+		go r.sampleStacks(ctx, logger)
+	}
+
+	if r.globalJobsCfg.MaxStackMB > 0 {
+		var cancelRoot context.CancelFunc
+
+		ctx, cancelRoot = context.WithCancel(ctx)
+		defer cancelRoot()
+
+		go r.watchStackUsage(ctx, cancelRoot, logger)
+	}
+
+	for _, path := range strings.Split(r.cfgOptions.PathsCSV, ",") {
+		if config.IsConfigMapURL(path) {
+			go r.watchConfigMap(ctx, path, logger)
+		}
+	}
+
 	refreshTimer := time.NewTicker(r.cfgOptions.RefreshTimeout)
 
 	defer refreshTimer.Stop()
 	metrics.IncClient()
 
+	// SIGTERM and Shutdown are only handled between config refreshes, until then they cancel the startup
+	startCtx, release := r.interruptible(ctx)
+
+	if err := r.runStartupProbe(startCtx, logger); err != nil {
+		if startCtx.Err() != nil {
+			r.stopBeforeStart(release(), logger)
+
+			return nil
+		}
+
+		release()
+		r.runShutdownHooks()
+
+		return err
+	}
+
+	rawConfig := r.fetchConfigWithContext(startCtx, lastKnownConfig, logger)
+	if interrupted := release(); interrupted || rawConfig == nil {
+		r.stopBeforeStart(interrupted, logger)
+
+		return nil
+	}
+
+	livenessErr := make(chan error, 1)
+	go r.watchLiveness(ctx, logger, livenessErr)
+
+	sentinel := make(chan struct{}, 1)
+	go r.watchSentinel(ctx, logger, sentinel)
+
 	var (
 		cancel  context.CancelFunc
 		tracker *metrics.StatsTracker
+		halted  bool
 	)
 
 	for {
-		rawConfig := config.FetchRawMultiConfig(logger, strings.Split(r.cfgOptions.PathsCSV, ","),
-			nonNilConfigOrDefault(lastKnownConfig, &config.RawMultiConfig{
-				Body: []byte(nonEmptyStringOrDefault(r.cfgOptions.BackupConfig, config.DefaultConfig)),
-			}), r.globalJobsCfg.SkipEncrypted)
+		if r.poisonPillTriggered(ctx, logger) {
+			logger.Warn("poison pill received, exiting", zap.String("url", r.globalJobsCfg.PoisonPillURL))
+			r.stopJobs(cancel, tracker, logger)
+
+			return nil
+		}
+
+		if rawConfig == nil { // the initial config is fetched before the loop
+			rawConfig = r.fetchConfig(lastKnownConfig, logger)
+		}
+
 		cfg := config.Unmarshal(rawConfig.Body, r.cfgOptions.Format)
 
-		if !bytes.Equal(lastKnownConfig.Body, rawConfig.Body) && cfg != nil { // Only restart jobs if the new config differs from the current one
+		changed := !bytes.Equal(lastKnownConfig.Body, rawConfig.Body) && cfg != nil // Only restart jobs if the new config differs from the current one
+
+		switch {
+		case halted:
+			logger.Info("sentinel has been triggered, not starting any jobs")
+		case !changed:
+			logger.Info("the config has not changed. Keep calm and carry on!")
+		case !dependencyReachable(ctx, cfg.DependsOnURL):
+			logger.Info("config dependency is not reachable, waiting for the next refresh", zap.String("url", cfg.DependsOnURL))
+		case !r.readinessProbePassed(ctx, logger):
+			// keep running the last known good config, it will be retried on the next refresh
+		default:
 			logger.Info("new config received, applying")
 
 			lastKnownConfig = rawConfig
@@ -114,6 +353,7 @@ func (r *Runner) Run(ctx context.Context, logger *zap.Logger) {
 
 			metric := &metrics.Metrics{} // clear info about previous targets and avoid old jobs from dumping old info to new metrics
 			tracker = metrics.NewStatsTracker(metric)
+			r.aggregated = metrics.NewAggregatedReporter(logger, nil)
 
 			if rawConfig.Protected {
 				logger.Info("config is protected, disabling logs")
@@ -122,25 +362,141 @@ func (r *Runner) Run(ctx context.Context, logger *zap.Logger) {
 			} else {
 				cancel = r.runJobs(ctx, cfg, metric, logger)
 			}
-		} else {
-			logger.Info("the config has not changed. Keep calm and carry on!")
 		}
 
 		// Wait for refresh timer or stop signal
 		select {
 		case <-refreshTimer.C:
+		case <-r.reload:
+			logger.Info("config reload requested")
+		case <-sentinel:
+			if cancel != nil {
+				cancel()
+				cancel = nil
+			}
+
+			halted = true
+		case err := <-livenessErr:
+			r.stopJobs(cancel, tracker, logger)
+
+			return fmt.Errorf("liveness probe failed: %w", err)
+		case <-r.stop:
+			r.stopJobs(cancel, tracker, logger)
+
+			return nil
+		case <-r.signals:
+			// restore the default handler so that another SIGTERM kills the process if jobs hang
+			signal.Stop(r.signals)
+
+			jobs := r.active.Statuses()
+
+			if cancel != nil {
+				cancel()
+			}
+
+			if !r.waitJobs() {
+				logger.Warn("some jobs didn't stop in time", zap.Int("count", len(r.active.Statuses())))
+			}
+
+			if err := writeFinalMetrics(r.cfgOptions.FinalMetricsPath, r.finalMetrics(tracker, jobs, start)); err != nil {
+				logger.Warn("failed to write final metrics", zap.Error(err))
+			}
+
+			r.runShutdownHooks()
+
+			return nil
 		case <-ctx.Done():
 			if cancel != nil {
 				cancel()
 			}
 
-			return
+			return nil
+		}
+
+		r.reportMetrics(tracker, logger)
+
+		rawConfig = nil
+	}
+}
+
+func (r *Runner) fetchConfig(lastKnownConfig *config.RawMultiConfig, logger *zap.Logger) *config.RawMultiConfig {
+	return config.FetchRawMultiConfig(logger, strings.Split(r.cfgOptions.PathsCSV, ","),
+		nonNilConfigOrDefault(lastKnownConfig, &config.RawMultiConfig{
+			Body: []byte(nonEmptyStringOrDefault(r.cfgOptions.BackupConfig, config.DefaultConfig)),
+		}), r.globalJobsCfg.SkipEncrypted)
+}
+
+// fetchConfigWithContext returns nil if ctx is done before the config is fetched, the fetch itself is left to finish in background
+func (r *Runner) fetchConfigWithContext(ctx context.Context, lastKnownConfig *config.RawMultiConfig, logger *zap.Logger) *config.RawMultiConfig {
+	fetched := make(chan *config.RawMultiConfig, 1)
+
+	go func() { fetched <- r.fetchConfig(lastKnownConfig, logger) }()
+
+	select {
+	case rawConfig := <-fetched:
+		return rawConfig
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+// interruptible returns a context that is cancelled as soon as SIGTERM is received or Shutdown is called.
+// release stops watching for them and reports whether the context has been interrupted.
+func (r *Runner) interruptible(ctx context.Context) (interruptibleCtx context.Context, release func() bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	released := make(chan struct{})
+	interrupted := make(chan bool, 1)
+
+	go func() {
+		select {
+		case <-r.signals:
+			// restore the default handler so that another SIGTERM kills the process if the startup hangs
+			signal.Stop(r.signals)
+			cancel()
+
+			interrupted <- true
+		case <-r.stop:
+			cancel()
+
+			interrupted <- true
+		case <-released:
+			interrupted <- false
 		}
+	}()
+
+	return ctx, func() bool {
+		defer cancel()
 
-		reportMetrics(r.reporter, tracker, r.globalJobsCfg.ClientID, logger)
+		close(released)
+
+		return <-interrupted
 	}
 }
 
+// stopBeforeStart is the shutdown path for when Run returns before any jobs were started
+func (r *Runner) stopBeforeStart(interrupted bool, logger *zap.Logger) {
+	if !interrupted {
+		return
+	}
+
+	logger.Info("stopped before starting any jobs")
+	r.runShutdownHooks()
+}
+
+// stopJobs cancels running jobs, waits for them to stop, reports metrics for the last time and calls shutdown hooks
+func (r *Runner) stopJobs(cancel context.CancelFunc, tracker *metrics.StatsTracker, logger *zap.Logger) {
+	if cancel != nil {
+		cancel()
+	}
+
+	if !r.waitJobs() {
+		logger.Warn("some jobs didn't stop in time", zap.Int("count", len(r.active.Statuses())))
+	}
+
+	r.reportMetrics(tracker, logger)
+	r.runShutdownHooks()
+}
+
 func nonEmptyStringOrDefault(s, defaultString string) string {
 	if s != "" {
 		return s
@@ -177,15 +533,32 @@ func (r *Runner) runJobs(ctx context.Context, cfg *config.MultiConfig, metric *m
 	ctx = context.WithValue(ctx, templates.ContextKey("goarch"), runtime.GOARCH)
 	ctx = context.WithValue(ctx, templates.ContextKey("version"), ota.Version)
 
+	globalConfig := r.globalJobsCfg
+	if cfg.ResourceQuota != nil {
+		limited := *r.globalJobsCfg
+		limited.ScaleFactor = r.quotaScaleFactor(*cfg.ResourceQuota, logger)
+		globalConfig = &limited
+	}
+
 	var jobInstancesCount int
 
+	aggregated := r.aggregated
+
+	go r.active.watchHealth(ctx, globalConfig, logger)
+
 	for i := range cfg.Jobs {
-		if len(cfg.Jobs[i].Filter) != 0 && strings.TrimSpace(templates.ParseAndExecute(logger, cfg.Jobs[i].Filter, ctx)) != "true" {
+		if !filterPasses(ctx, cfg.Jobs[i].Filter, logger) {
 			logger.Info("There is a filter defined for a job but this client doesn't pass it - skip the job")
 
 			continue
 		}
 
+		if !selectorMatches(ctx, cfg.Jobs[i], logger) {
+			logger.Info("There is a selector defined for a job but this client doesn't match it - skip the job")
+
+			continue
+		}
+
 		job := Get(cfg.Jobs[i].Type)
 		if job == nil {
 			logger.Warn("unknown job", zap.String("type", cfg.Jobs[i].Type))
@@ -193,8 +566,8 @@ func (r *Runner) runJobs(ctx context.Context, cfg *config.MultiConfig, metric *m
 			continue
 		}
 
-		if r.globalJobsCfg.ScaleFactor > 0 {
-			cfg.Jobs[i].Count = computeCount(cfg.Jobs[i].Count, r.globalJobsCfg.ScaleFactor)
+		if globalConfig.ScaleFactor > 0 {
+			cfg.Jobs[i].Count = computeCount(cfg.Jobs[i].Count, globalConfig.ScaleFactor)
 		}
 
 		cfgMap := make(map[string]any)
@@ -210,15 +583,48 @@ func (r *Runner) runJobs(ctx context.Context, cfg *config.MultiConfig, metric *m
 				logger.Info("Attacking", zap.String("target", cfg.Jobs[i].Name))
 			}
 
+			r.jobs.Add(1)
+
 			go func(i int) {
+				defer r.jobs.Done()
 				defer utils.PanicHandler(logger)
 
-				if _, err := job(ctx, cfg.Jobs[i].Args, r.globalJobsCfg, metric.NewAccumulator(uuid.NewString()), logger); err != nil {
+				if !utils.Sleep(ctx, launchDelay(globalConfig.LaunchJitter)) {
+					return
+				}
+
+				release, ok := isolate(ctx, cfg.Jobs[i].IsolationMode, cfg.Jobs[i].Type)
+				if !ok {
+					return
+				}
+				defer release()
+
+				id := uuid.NewString()
+				acc := metric.NewAccumulator(id)
+
+				// named jobs are also reported separately, instances of the same job are merged
+				if aggregated != nil && acc != nil && cfg.Jobs[i].Name != "" {
+					aggregated.Add(metrics.NamedAccumulator{Name: cfg.Jobs[i].Name, Accumulator: acc})
+				}
+
+				r.active.add(id, cfg.Jobs[i], acc)
+				defer r.active.remove(id)
+
+				start := time.Now()
+				_, err := job(ctx, cfg.Jobs[i].Args, globalConfig, acc, logger)
+				entry := utils.JobHistoryEntry{Timestamp: start, JobName: cfg.Jobs[i].Name, JobType: cfg.Jobs[i].Type, Duration: time.Since(start)}
+
+				if err != nil {
+					entry.Error = err.Error()
+
 					logger.Error("error running job",
 						zap.String("name", cfg.Jobs[i].Name),
 						zap.String("type", cfg.Jobs[i].Type),
+						zap.Float64("health_score", acc.HealthScore()),
 						zap.Error(err))
 				}
+
+				r.history.Push(entry)
 			}(i)
 
 			jobInstancesCount++
@@ -230,12 +636,154 @@ func (r *Runner) runJobs(ctx context.Context, cfg *config.MultiConfig, metric *m
 	return cancel
 }
 
-func reportMetrics(reporter metrics.Reporter, tracker *metrics.StatsTracker, clientID string, logger *zap.Logger) {
-	if reporter != nil && tracker != nil {
-		reporter.WriteSummary(tracker)
+// launchDelay returns a random delay in [0, jitter) to desynchronize job instances started at the same time
+func launchDelay(jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(jitter))) //nolint:gosec // Cryptographically secure random not required
+}
+
+// filterPasses evaluates job filters written either as a go template or as a JSON Logic rule (a JSON object)
+func filterPasses(ctx context.Context, filter string, logger *zap.Logger) bool {
+	if len(filter) == 0 {
+		return true
+	}
+
+	if trimmed := strings.TrimSpace(filter); !strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "{{") {
+		return strings.TrimSpace(templates.ParseAndExecute(logger, filter, ctx)) == "true"
+	}
+
+	result, err := utils.EvalJSONLogic(filter, func(name string) any { return ctx.Value(templates.ContextKey(name)) })
+	if err != nil {
+		logger.Warn("invalid job filter", zap.String("filter", filter), zap.Error(err))
+
+		return false
+	}
+
+	return result
+}
+
+func selectorMatches(ctx context.Context, cfg config.Config, logger *zap.Logger) bool {
+	if cfg.Selector == "" {
+		return true
+	}
+
+	selector, err := utils.ParseLabelSelector(cfg.Selector)
+	if err != nil {
+		logger.Warn("invalid job selector", zap.String("selector", cfg.Selector), zap.Error(err))
+
+		return false
+	}
+
+	labels := make(map[string]string, len(cfg.Labels))
+	for k, v := range cfg.Labels {
+		labels[k] = strings.TrimSpace(templates.ParseAndExecute(logger, v, ctx))
+	}
+
+	return selector.Matches(labels)
+}
+
+func (r *Runner) sampleStacks(ctx context.Context, logger *zap.Logger) {
+	const maxProfileSize = 10 << 20
+
+	if err := utils.SampleStacks(ctx, utils.StackSamplerConfig{
+		Interval: r.cfgOptions.SampleProfileInterval,
+		Path:     r.cfgOptions.SampleProfilePath,
+		MaxBytes: maxProfileSize,
+	}); err != nil {
+		logger.Warn("goroutine stack sampling stopped", zap.Error(err))
+	}
+}
+
+// watchStackUsage stops everything if goroutine stacks grow too much, e.g. due to runaway recursion in a template or script
+func (r *Runner) watchStackUsage(ctx context.Context, cancel context.CancelFunc, logger *zap.Logger) {
+	const (
+		checkInterval = 5 * time.Second
+		bytesInMB     = 1 << 20
+	)
+
+	utils.WatchStackUsage(ctx, uint64(r.globalJobsCfg.MaxStackMB)*bytesInMB, checkInterval, func(inuse uint64) {
+		logger.Error("goroutine stacks exceeded the limit, stopping",
+			zap.Uint64("stack_inuse_mb", inuse/bytesInMB), zap.Int("max_stack_mb", r.globalJobsCfg.MaxStackMB),
+			zap.Any("top_stacks", utils.TakeStackSnapshot().TopStacks))
+
+		cancel()
+	})
+}
+
+// dependencyReachable reports whether the url a config depends on responds at all, empty url means no dependency
+func dependencyReachable(ctx context.Context, url string) bool {
+	const requestTimeout = 5 * time.Second
+
+	if url == "" {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+
+	resp.Body.Close()
+
+	return true
+}
+
+// quotaScaleFactor reduces the scale factor proportionally to how much current resource usage exceeds the quota
+func (r *Runner) quotaScaleFactor(quota config.ResourceQuota, logger *zap.Logger) float64 {
+	scaleFactor := r.globalJobsCfg.ScaleFactor
+	ratio := 1.0
+
+	memoryMB, cpuPct := r.resources.MemoryMB(), r.resources.CPUPct()
+
+	if quota.MaxMemoryMB > 0 && memoryMB > quota.MaxMemoryMB {
+		ratio = math.Min(ratio, quota.MaxMemoryMB/memoryMB)
+	}
+
+	if quota.MaxCPUPct > 0 && cpuPct > quota.MaxCPUPct {
+		ratio = math.Min(ratio, quota.MaxCPUPct/cpuPct)
+	}
+
+	if ratio >= 1 {
+		return scaleFactor
+	}
+
+	if scaleFactor <= 0 {
+		scaleFactor = 1
+	}
+
+	logger.Warn("resource quota exceeded, reducing scale factor",
+		zap.Float64("memory_mb", memoryMB), zap.Float64("max_memory_mb", quota.MaxMemoryMB),
+		zap.Float64("cpu_pct", cpuPct), zap.Float64("max_cpu_pct", quota.MaxCPUPct),
+		zap.Float64("scale_factor", scaleFactor*ratio))
+
+	return scaleFactor * ratio
+}
+
+func (r *Runner) reportMetrics(tracker *metrics.StatsTracker, logger *zap.Logger) {
+	if tracker == nil {
+		return
+	}
+
+	if r.aggregated != nil {
+		r.aggregated.WriteSummary(tracker)
+	}
+
+	if r.reporter != nil {
+		r.reporter.WriteSummary(tracker)
 
 		// TODO: get rid of this
-		if err := metrics.ReportStatistics(0, clientID); err != nil {
+		if err := metrics.ReportStatistics(0, r.globalJobsCfg.ClientID); err != nil {
 			logger.Debug("error reporting statistics", zap.Error(err))
 		}
 	}
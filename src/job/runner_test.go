@@ -0,0 +1,326 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils/metrics"
+)
+
+func TestRunnerWritesFinalMetricsOnSIGTERM(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	configPath, metricsPath := filepath.Join(dir, "config.yaml"), filepath.Join(dir, "metrics.json")
+
+	const cfg = `jobs:
+  - type: loop
+    count: 1
+    args:
+      interval_ms: 10
+      job:
+        type: sleep
+        args:
+          value: 1ms
+`
+
+	if err := os.WriteFile(configPath, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewRunner(&ConfigOptions{PathsCSV: configPath, Format: "yaml", RefreshTimeout: time.Hour, FinalMetricsPath: metricsPath},
+		&GlobalConfig{ClientID: "test-client"}, nil)
+	runner.signals = make(chan os.Signal, 1)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = runner.Run(context.Background(), zap.NewNop())
+	}()
+
+	for deadline := time.Now().Add(5 * time.Second); len(runner.ActiveJobs().Statuses()) == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("job didn't start")
+		}
+	}
+
+	runner.signals <- syscall.SIGTERM
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runner didn't stop on SIGTERM")
+	}
+
+	content, err := os.ReadFile(metricsPath)
+	if err != nil {
+		t.Fatalf("final metrics weren't written: %v", err)
+	}
+
+	var dump map[string]any
+	if err := json.Unmarshal(content, &dump); err != nil {
+		t.Fatalf("invalid final metrics %q: %v", content, err)
+	}
+
+	if dump["client_id"] != "test-client" {
+		t.Errorf("unexpected client_id %v", dump["client_id"])
+	}
+
+	for _, key := range []string{"final_metrics", "run_duration"} {
+		if _, ok := dump[key]; !ok {
+			t.Errorf("missing %q in %s", key, content)
+		}
+	}
+
+	if jobs, ok := dump["jobs"].([]any); !ok || len(jobs) != 1 {
+		t.Errorf("expected a summary of the running job, got %v", dump["jobs"])
+	}
+}
+
+func TestRunnerDoesNotHangOnSIGTERMWithStuckJobs(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("jobs: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewRunner(&ConfigOptions{
+		PathsCSV: configPath, Format: "yaml", RefreshTimeout: time.Hour,
+		FinalMetricsPath: filepath.Join(t.TempDir(), "metrics.json"), ShutdownTimeout: 50 * time.Millisecond,
+	}, &GlobalConfig{}, nil)
+	runner.signals = make(chan os.Signal, 1)
+
+	// a job instance that ignores cancellation
+	runner.jobs.Add(1)
+	defer runner.jobs.Done()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = runner.Run(context.Background(), zap.NewNop())
+	}()
+
+	runner.signals <- syscall.SIGTERM
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runner waited for a stuck job past the shutdown timeout")
+	}
+}
+
+func TestRunnerStopsOnSIGTERMDuringStartupProbe(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("jobs: [{type: sleep, args: {value: 1h}}]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewRunner(&ConfigOptions{PathsCSV: configPath, Format: "yaml", RefreshTimeout: time.Hour},
+		&GlobalConfig{StartupProbe: &config.Config{Type: "failing-probe"}}, nil) // retried every 10 seconds
+	runner.signals = make(chan os.Signal, 1)
+
+	var hooks int32
+
+	runner.OnShutdown(func() { atomic.AddInt32(&hooks, 1) })
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = runner.Run(context.Background(), zap.NewNop())
+	}()
+
+	runner.signals <- syscall.SIGTERM
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runner didn't stop on SIGTERM during the startup probe")
+	}
+
+	if n := len(runner.ActiveJobs().Statuses()); n != 0 {
+		t.Errorf("expected no jobs to be started, got %d", n)
+	}
+
+	if atomic.LoadInt32(&hooks) != 1 {
+		t.Error("expected shutdown hooks to run")
+	}
+}
+
+func TestRunnerStopsJobsWhenLivenessProbeFails(t *testing.T) {
+	t.Parallel()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("jobs: [{type: loop, count: 1, args: {interval_ms: 10, job: {type: sleep, args: {value: 1ms}}}}]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewRunner(&ConfigOptions{PathsCSV: configPath, Format: "yaml", RefreshTimeout: time.Hour}, &GlobalConfig{
+		LivenessProbe: &config.Config{Type: "failing-probe"}, LivenessInterval: 10 * time.Millisecond, LivenessThreshold: 2,
+	}, nil)
+	runner.signals = make(chan os.Signal, 1)
+
+	var hooks int32
+
+	runner.OnShutdown(func() { atomic.AddInt32(&hooks, 1) })
+
+	result := make(chan error, 1)
+
+	go func() { result <- runner.Run(context.Background(), zap.NewNop()) }()
+
+	select {
+	case err := <-result:
+		if err == nil {
+			t.Error("expected the liveness failure to be returned")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runner didn't stop when the liveness probe failed")
+	}
+
+	if n := len(runner.ActiveJobs().Statuses()); n != 0 {
+		t.Errorf("expected jobs to be stopped, %d are still running", n)
+	}
+
+	if atomic.LoadInt32(&hooks) != 1 {
+		t.Error("expected shutdown hooks to run")
+	}
+}
+
+func TestLaunchJitterDesynchronizesInstances(t *testing.T) {
+	t.Parallel()
+
+	const (
+		instances = 10
+		jitter    = 200 * time.Millisecond
+	)
+
+	cfg := &config.MultiConfig{Jobs: []config.Config{{
+		Type:  "loop",
+		Count: instances,
+		Args:  config.Args{"interval_ms": 10, "job": map[string]any{"type": "sleep", "args": map[string]any{"value": "1ms"}}},
+	}}}
+
+	runner := NewRunner(&ConfigOptions{}, &GlobalConfig{LaunchJitter: jitter}, nil)
+	cancel := runner.runJobs(context.Background(), cfg, &metrics.Metrics{}, zap.NewNop())
+
+	defer func() {
+		cancel()
+		runner.jobs.Wait()
+	}()
+
+	var statuses []ActiveJobStatus
+
+	for deadline := time.Now().Add(5 * time.Second); len(statuses) < instances; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("only %d of %d instances started", len(statuses), instances)
+		}
+
+		statuses = runner.ActiveJobs().Statuses()
+	}
+
+	// statuses are sorted by start time
+	averageGap := statuses[len(statuses)-1].Started.Sub(statuses[0].Started) / (instances - 1)
+	if averageGap <= time.Millisecond {
+		t.Errorf("expected instances to start more than 1ms apart on average, got %v", averageGap)
+	}
+}
+
+func TestRunJobsReportsNamedJobs(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.MultiConfig{Jobs: []config.Config{
+		{Name: "named", Type: "loop", Count: 2, Args: config.Args{"interval_ms": 10, "job": map[string]any{"type": "sleep", "args": map[string]any{"value": "1ms"}}}},
+		{Type: "loop", Count: 1, Args: config.Args{"interval_ms": 10, "job": map[string]any{"type": "sleep", "args": map[string]any{"value": "1ms"}}}},
+	}}
+	metric := &metrics.Metrics{}
+
+	runner := NewRunner(&ConfigOptions{}, &GlobalConfig{}, nil)
+	runner.aggregated = metrics.NewAggregatedReporter(zap.NewNop(), nil)
+	cancel := runner.runJobs(context.Background(), cfg, metric, zap.NewNop())
+
+	defer func() {
+		cancel()
+		runner.jobs.Wait()
+	}()
+
+	for deadline := time.Now().Add(5 * time.Second); len(runner.ActiveJobs().Statuses()) < 3; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("jobs didn't start")
+		}
+	}
+
+	perAccumulator, _ := runner.aggregated.Summary(metrics.NewStatsTracker(metric))
+	if _, ok := perAccumulator["named"]; !ok || len(perAccumulator) != 1 {
+		t.Errorf("expected only the named job to be reported, got %v", perAccumulator)
+	}
+}
+
+func TestWatchSentinelWithNonPositiveInterval(t *testing.T) {
+	t.Parallel()
+
+	runner := NewRunner(&ConfigOptions{}, &GlobalConfig{SentinelURL: "http://127.0.0.1:1", SentinelInterval: 0}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// must not panic and has to stop with the context
+	runner.watchSentinel(ctx, zap.NewNop(), make(chan struct{}, 1))
+}
+
+func TestPoisonPillStopsRunnerGracefully(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte("jobs: []\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewRunner(&ConfigOptions{PathsCSV: configPath, Format: "yaml", RefreshTimeout: time.Hour},
+		&GlobalConfig{PoisonPillURL: server.URL}, nil)
+	runner.signals = make(chan os.Signal, 1)
+
+	hookCalled := make(chan struct{})
+	runner.OnShutdown(func() { close(hookCalled) })
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		_ = runner.Run(context.Background(), zap.NewNop())
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runner didn't stop on poison pill")
+	}
+
+	select {
+	case <-hookCalled:
+	default:
+		t.Error("shutdown hooks weren't called")
+	}
+}
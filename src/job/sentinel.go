@@ -0,0 +1,106 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// watchSentinel polls the sentinel url and signals once it responds with the configured status
+func (r *Runner) watchSentinel(ctx context.Context, logger *zap.Logger, triggered chan<- struct{}) {
+	if r.globalJobsCfg.SentinelURL == "" {
+		return
+	}
+
+	interval := r.globalJobsCfg.SentinelInterval
+	if interval <= 0 {
+		interval = defaultSentinelInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		status, err := sentinelStatus(ctx, r.globalJobsCfg.SentinelURL)
+		if err != nil {
+			logger.Debug("failed to check sentinel", zap.Error(err))
+
+			continue
+		}
+
+		if status == r.globalJobsCfg.SentinelStatus {
+			logger.Warn("sentinel triggered, stopping all jobs", zap.String("url", r.globalJobsCfg.SentinelURL), zap.Int("status", status))
+
+			triggered <- struct{}{}
+
+			return
+		}
+	}
+}
+
+func sentinelStatus(ctx context.Context, url string) (int, error) {
+	const requestTimeout = 10 * time.Second
+
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// poisonPillTriggered reports whether the poison pill url is set and responds with 200
+func (r *Runner) poisonPillTriggered(ctx context.Context, logger *zap.Logger) bool {
+	if r.globalJobsCfg.PoisonPillURL == "" {
+		return false
+	}
+
+	status, err := sentinelStatus(ctx, r.globalJobsCfg.PoisonPillURL)
+	if err != nil {
+		logger.Debug("failed to check poison pill", zap.Error(err))
+
+		return false
+	}
+
+	return status == http.StatusOK
+}
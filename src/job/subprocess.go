@@ -0,0 +1,285 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils"
+	"github.com/Arriven/db1000n/src/utils/metrics"
+)
+
+// RunSingleJobFlag makes db1000n read a single job from stdin, run it and write its metrics to stdout
+const RunSingleJobFlag = "run-single-job"
+
+// subprocessPayload is passed to the child process through stdin
+type subprocessPayload struct {
+	Job    config.Config
+	Global GlobalConfig
+}
+
+// "subprocess" in config
+func subprocessJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (
+	data any, err error,
+) {
+	var jobConfig struct {
+		Job      config.Config
+		MaxMemMB int
+		Timeout  time.Duration
+	}
+
+	if err := utils.Decode(args, &jobConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	payload, err := json.Marshal(subprocessPayload{Job: jobConfig.Job, Global: *globalConfig})
+	if err != nil {
+		return nil, fmt.Errorf("error encoding child job config: %w", err)
+	}
+
+	if jobConfig.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, jobConfig.Timeout)
+		defer cancel()
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("error locating executable: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, executable, "-"+RunSingleJobFlag)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stderr = os.Stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting child process: %w", err)
+	}
+
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+
+	var memExceeded bool
+
+	var wg sync.WaitGroup
+
+	if jobConfig.MaxMemMB > 0 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			memExceeded = watchChildMemory(watchCtx, cmd.Process, jobConfig.MaxMemMB, logger)
+		}()
+	}
+
+	collectChildMetrics(stdout, a, logger)
+
+	err = cmd.Wait()
+
+	stopWatch()
+	wg.Wait()
+
+	switch {
+	case memExceeded:
+		err = fmt.Errorf("child process exceeded memory limit of %d MB", jobConfig.MaxMemMB)
+	case err != nil && ctx.Err() == nil:
+		err = fmt.Errorf("child process failed: %w", err)
+	default:
+		return nil, nil
+	}
+
+	logger.Warn("subprocess job failed", zap.String("type", jobConfig.Job.Type), zap.Error(err))
+
+	return nil, err
+}
+
+// collectChildMetrics reads cumulative per-target stats reported by the child and adds increments to the accumulator
+func collectChildMetrics(in io.Reader, a *metrics.Accumulator, logger *zap.Logger) {
+	const maxLineSize = 1 << 20
+
+	last := make(metrics.PerTargetStats)
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, maxLineSize)
+
+	for scanner.Scan() {
+		var stats metrics.PerTargetStats
+		if err := json.Unmarshal(scanner.Bytes(), &stats); err != nil {
+			logger.Debug("unexpected child process output", zap.ByteString("line", scanner.Bytes()))
+
+			continue
+		}
+
+		// the output still has to be drained if there is nowhere to report to
+		if a == nil {
+			continue
+		}
+
+		for target, targetStats := range stats {
+			diff := metrics.Diff(targetStats, last[target])
+			for s := metrics.RequestsAttemptedStat; s < metrics.NumStats; s++ {
+				a.Add(target, s, diff[s])
+			}
+
+			last[target] = targetStats
+		}
+
+		a.Flush()
+	}
+}
+
+// watchChildMemory kills the process once its resident memory exceeds maxMemMB, returns whether it did so.
+// Memory is read from procfs so the limit is only enforced on linux.
+func watchChildMemory(ctx context.Context, process *os.Process, maxMemMB int, logger *zap.Logger) bool {
+	const checkInterval = time.Second
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+
+		rssMB, err := processRSSMB(process.Pid)
+		if err != nil {
+			logger.Debug("can't read child process memory usage", zap.Error(err))
+
+			return false
+		}
+
+		if rssMB > maxMemMB {
+			if err := process.Kill(); err != nil {
+				logger.Warn("failed to kill child process", zap.Error(err))
+			}
+
+			return true
+		}
+	}
+}
+
+func processRSSMB(pid int) (int, error) {
+	const kbInMB = 1024
+
+	status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, line := range strings.Split(string(status), "\n") {
+		if !strings.HasPrefix(line, "VmRSS:") {
+			continue
+		}
+
+		fields := strings.Fields(strings.TrimPrefix(line, "VmRSS:"))
+		if len(fields) == 0 {
+			break
+		}
+
+		rssKB, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return 0, err
+		}
+
+		return rssKB / kbInMB, nil
+	}
+
+	return 0, errors.New("VmRSS not found")
+}
+
+// RunSingleJob runs the job read from in and periodically writes its cumulative per-target stats to out as json lines.
+// It's the child side of the subprocess job.
+func RunSingleJob(ctx context.Context, in io.Reader, out io.Writer, logger *zap.Logger) error {
+	const reportInterval = time.Second
+
+	var payload subprocessPayload
+	if err := json.NewDecoder(in).Decode(&payload); err != nil {
+		return fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	job := Get(payload.Job.Type)
+	if job == nil {
+		return fmt.Errorf("unknown job %q", payload.Job.Type)
+	}
+
+	var data metrics.Metrics
+
+	encoder := json.NewEncoder(out)
+	report := func() {
+		stats, _ := data.SumAllStats(false)
+		if err := encoder.Encode(stats); err != nil {
+			logger.Debug("failed to report stats", zap.Error(err))
+		}
+	}
+
+	reportCtx, stopReporting := context.WithCancel(ctx)
+	reported := make(chan struct{})
+
+	go func() {
+		defer close(reported)
+
+		ticker := time.NewTicker(reportInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-reportCtx.Done():
+				return
+			case <-ticker.C:
+				report()
+			}
+		}
+	}()
+
+	_, err := job(ctx, payload.Job.Args, &payload.Global, data.NewAccumulator(uuid.NewString()), logger)
+
+	stopReporting()
+	<-reported
+	report()
+
+	return err
+}
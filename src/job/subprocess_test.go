@@ -0,0 +1,47 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// subprocessChildEnv makes the test binary act as the child process of the subprocess job
+const subprocessChildEnv = "DB1000N_TEST_SUBPROCESS_CHILD"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(subprocessChildEnv) != "" {
+		if err := RunSingleJob(context.Background(), os.Stdin, os.Stdout, zap.NewNop()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestSubprocessJobWithoutAccumulator(t *testing.T) {
+	t.Setenv(subprocessChildEnv, "1")
+
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	args := map[string]any{
+		"job": map[string]any{"type": "http", "args": map[string]any{
+			"count":   1,
+			"request": map[string]any{"method": "GET", "path": server.URL},
+		}},
+	}
+
+	// protected configs and probes run jobs without an accumulator
+	if _, err := subprocessJob(context.Background(), args, &GlobalConfig{}, nil, zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,115 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils"
+	"github.com/Arriven/db1000n/src/utils/metrics"
+)
+
+type tcNetemConfig struct {
+	Interface         string
+	DelayMs           int
+	JitterMs          int
+	PacketLossPercent float64
+	CorruptPercent    float64
+	Duration          time.Duration // zero keeps the qdisc until the job is cancelled
+	Confirm           bool
+}
+
+// "tc-netem" in config
+func tcNetemJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (
+	data any, err error, //nolint:unparam // data is here to match Job
+) {
+	var jobConfig tcNetemConfig
+
+	if err := utils.Decode(args, &jobConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	switch {
+	case runtime.GOOS != "linux":
+		return nil, fmt.Errorf("tc-netem is not supported on %s", runtime.GOOS)
+	case !jobConfig.Confirm:
+		return nil, errors.New("tc-netem changes the host network configuration, set confirm to true to run it")
+	case jobConfig.Interface == "":
+		return nil, errors.New("interface is required")
+	}
+
+	if output, err := exec.CommandContext(ctx, "tc", tcNetemAddArgs(jobConfig)...).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("error adding netem qdisc: %w: %s", err, output)
+	}
+
+	logger.Info("netem qdisc added", zap.String("interface", jobConfig.Interface), zap.Duration("duration", jobConfig.Duration))
+
+	defer func() {
+		// the qdisc has to be removed even if the job is cancelled
+		if output, err := exec.CommandContext(detachedContext{ctx}, "tc", tcNetemDelArgs(jobConfig)...).CombinedOutput(); err != nil {
+			logger.Warn("error removing netem qdisc", zap.String("interface", jobConfig.Interface), zap.Error(err), zap.ByteString("output", output))
+		}
+	}()
+
+	if jobConfig.Duration > 0 {
+		utils.Sleep(ctx, jobConfig.Duration)
+	} else {
+		<-ctx.Done()
+	}
+
+	return nil, nil
+}
+
+func tcNetemAddArgs(c tcNetemConfig) []string {
+	args := []string{"qdisc", "add", "dev", c.Interface, "root", "netem"}
+
+	if c.DelayMs > 0 {
+		args = append(args, "delay", strconv.Itoa(c.DelayMs)+"ms")
+		if c.JitterMs > 0 {
+			args = append(args, strconv.Itoa(c.JitterMs)+"ms")
+		}
+	}
+
+	if c.PacketLossPercent > 0 {
+		args = append(args, "loss", strconv.FormatFloat(c.PacketLossPercent, 'f', -1, 64)+"%")
+	}
+
+	if c.CorruptPercent > 0 {
+		args = append(args, "corrupt", strconv.FormatFloat(c.CorruptPercent, 'f', -1, 64)+"%")
+	}
+
+	return args
+}
+
+func tcNetemDelArgs(c tcNetemConfig) []string {
+	return []string{"qdisc", "del", "dev", c.Interface, "root"}
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTCNetemArgs(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		config   tcNetemConfig
+		expected string
+	}{
+		{
+			name:     "delay with jitter",
+			config:   tcNetemConfig{Interface: "eth0", DelayMs: 100, JitterMs: 20},
+			expected: "qdisc add dev eth0 root netem delay 100ms 20ms",
+		},
+		{
+			name:     "jitter without delay is ignored",
+			config:   tcNetemConfig{Interface: "eth0", JitterMs: 20, PacketLossPercent: 1.5},
+			expected: "qdisc add dev eth0 root netem loss 1.5%",
+		},
+		{
+			name:     "all options",
+			config:   tcNetemConfig{Interface: "lo", DelayMs: 50, JitterMs: 5, PacketLossPercent: 10, CorruptPercent: 0.1},
+			expected: "qdisc add dev lo root netem delay 50ms 5ms loss 10% corrupt 0.1%",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := strings.Join(tcNetemAddArgs(tc.config), " "); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	if got := strings.Join(tcNetemDelArgs(tcNetemConfig{Interface: "eth0"}), " "); got != "qdisc del dev eth0 root" {
+		t.Errorf("unexpected del args %q", got)
+	}
+}
@@ -0,0 +1,179 @@
+// MIT License
+
+// Copyright (c) [2022] [Bohdan Ivashko (https://github.com/Arriven)]
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package job
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/job/config"
+	"github.com/Arriven/db1000n/src/utils/metrics"
+	"github.com/Arriven/db1000n/src/utils/templates"
+)
+
+// Span describes a single job invocation in the trace file
+type Span struct {
+	StartNS      int64  `json:"start_ns"`
+	EndNS        int64  `json:"end_ns"`
+	JobType      string `json:"job_type"`
+	JobName      string `json:"job_name,omitempty"`
+	ParentSpanID uint64 `json:"parent_span_id,omitempty"`
+	SpanID       uint64 `json:"span_id"`
+}
+
+type spanKey struct{}
+
+type tracer struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	lastID  uint64
+}
+
+// jobTracer is set once on startup before any jobs are started so it's safe to read without locking
+var jobTracer *tracer
+
+// EnableTracing makes every job invocation append a span to the file at path.
+// Has to be called before any jobs are started.
+func EnableTracing(path string) error {
+	file, err := openTraceFile(path)
+	if err != nil {
+		return err
+	}
+
+	jobTracer = &tracer{encoder: json.NewEncoder(file)}
+
+	return nil
+}
+
+// openTraceFile truncates the file since span ids start over on every run and spans of different runs can't be told apart
+func openTraceFile(path string) (*os.File, error) {
+	const perm = 0o600
+
+	return os.OpenFile(path, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, perm)
+}
+
+func (t *tracer) wrap(jobType string, job Job) Job {
+	return func(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
+		span := Span{
+			StartNS: time.Now().UnixNano(),
+			JobType: jobType,
+			JobName: topLevelJobName(ctx),
+			SpanID:  atomic.AddUint64(&t.lastID, 1),
+		}
+		span.ParentSpanID, _ = ctx.Value(spanKey{}).(uint64)
+
+		defer func() {
+			span.EndNS = time.Now().UnixNano()
+			t.record(span)
+		}()
+
+		return job(context.WithValue(ctx, spanKey{}, span.SpanID), args, globalConfig, a, logger)
+	}
+}
+
+func (t *tracer) record(span Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_ = t.encoder.Encode(span)
+}
+
+// topLevelJobName returns the name of the config entry the job was started from, nested jobs don't have names of their own
+func topLevelJobName(ctx context.Context) string {
+	cfg, ok := ctx.Value(templates.ContextKey("config")).(map[string]any)
+	if !ok {
+		return ""
+	}
+
+	name, _ := cfg["Name"].(string)
+
+	return name
+}
+
+type chromeTraceEvent struct {
+	Name  string         `json:"name"`
+	Cat   string         `json:"cat"`
+	Phase string         `json:"ph"`
+	TS    float64        `json:"ts"`
+	Dur   float64        `json:"dur"`
+	PID   int            `json:"pid"`
+	TID   uint64         `json:"tid"`
+	Args  map[string]any `json:"args,omitempty"`
+}
+
+// RenderTrace converts a jsonl span file into chrome trace format (chrome://tracing), every top level job gets its own row
+func RenderTrace(in io.Reader, out io.Writer) error {
+	const nsInMicrosecond = 1000
+
+	var spans []Span
+
+	decoder := json.NewDecoder(bufio.NewReader(in))
+	for {
+		var span Span
+		if err := decoder.Decode(&span); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		spans = append(spans, span)
+	}
+
+	parents := make(map[uint64]uint64, len(spans))
+	for _, span := range spans {
+		parents[span.SpanID] = span.ParentSpanID
+	}
+
+	root := func(id uint64) uint64 {
+		for parents[id] != 0 {
+			id = parents[id]
+		}
+
+		return id
+	}
+
+	events := make([]chromeTraceEvent, 0, len(spans))
+	for _, span := range spans {
+		events = append(events, chromeTraceEvent{
+			Name:  span.JobType,
+			Cat:   "job",
+			Phase: "X",
+			TS:    float64(span.StartNS) / nsInMicrosecond,
+			Dur:   float64(span.EndNS-span.StartNS) / nsInMicrosecond,
+			PID:   1,
+			TID:   root(span.SpanID),
+			Args:  map[string]any{"name": span.JobName, "span_id": span.SpanID, "parent_span_id": span.ParentSpanID},
+		})
+	}
+
+	return json.NewEncoder(out).Encode(map[string]any{"traceEvents": events})
+}
@@ -0,0 +1,74 @@
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// two top level jobs, the first one with a nested job that has a child of its own
+const traceFixture = `{"start_ns": 1000, "end_ns": 5000, "job_type": "sleep", "span_id": 3, "parent_span_id": 2}
+{"start_ns": 1000, "end_ns": 6000, "job_type": "loop", "span_id": 2, "parent_span_id": 1}
+{"start_ns": 0, "end_ns": 8000, "job_type": "sequence", "job_name": "first", "span_id": 1}
+{"start_ns": 2000, "end_ns": 4000, "job_type": "http", "job_name": "second", "span_id": 4}
+`
+
+func TestRenderTrace(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	if err := RenderTrace(strings.NewReader(traceFixture), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var trace struct {
+		TraceEvents []chromeTraceEvent `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &trace); err != nil {
+		t.Fatalf("invalid trace %q: %v", out.String(), err)
+	}
+
+	expected := []struct {
+		name    string
+		row     uint64
+		ts, dur float64
+	}{
+		{name: "sleep", row: 1, ts: 1, dur: 4},
+		{name: "loop", row: 1, ts: 1, dur: 5},
+		{name: "sequence", row: 1, ts: 0, dur: 8},
+		{name: "http", row: 4, ts: 2, dur: 2},
+	}
+
+	if len(trace.TraceEvents) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(trace.TraceEvents))
+	}
+
+	for i, event := range trace.TraceEvents {
+		if e := expected[i]; event.Name != e.name || event.TID != e.row || event.TS != e.ts || event.Dur != e.dur {
+			t.Errorf("event %d: expected %+v, got %+v", i, e, event)
+		}
+	}
+}
+
+func TestOpenTraceFileTruncatesPreviousRun(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "trace.jsonl")
+	if err := os.WriteFile(path, []byte(traceFixture), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := openTraceFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file.Close()
+
+	if content, err := os.ReadFile(path); err != nil || len(content) != 0 {
+		t.Errorf("expected spans of the previous run to be dropped, got %q (%v)", content, err)
+	}
+}
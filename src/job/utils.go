@@ -25,7 +25,12 @@ package job
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"os"
 	"strconv"
 	"time"
 
@@ -147,6 +152,65 @@ func discardErrorJob(ctx context.Context, args config.Args, globalConfig *Global
 	return data, nil
 }
 
+// "cleanup-on-exit" in config
+// Cleanup runs even if the main job was cancelled but is limited by timeout (30s by default).
+func cleanupOnExitJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
+	const defaultCleanupTimeout = 30 * time.Second
+
+	var jobConfig struct {
+		BasicJobConfig
+
+		Main    config.Config
+		Cleanup config.Config
+		Timeout time.Duration
+	}
+
+	if err := ParseConfig(&jobConfig, args, *globalConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	mainJob, cleanupJob := Get(jobConfig.Main.Type), Get(jobConfig.Cleanup.Type)
+	if mainJob == nil {
+		return nil, fmt.Errorf("unknown job %q", jobConfig.Main.Type)
+	}
+
+	if cleanupJob == nil {
+		return nil, fmt.Errorf("unknown job %q", jobConfig.Cleanup.Type)
+	}
+
+	if jobConfig.Timeout <= 0 {
+		jobConfig.Timeout = defaultCleanupTimeout
+	}
+
+	defer func() {
+		var mainError string
+		if err != nil {
+			mainError = err.Error()
+		}
+
+		// cleanup has to run even if the main job was cancelled
+		cleanupCtx, cancel := context.WithTimeout(detachedContext{ctx}, jobConfig.Timeout)
+		defer cancel()
+
+		cleanupCtx = context.WithValue(cleanupCtx, templates.ContextKey("main_error"), mainError)
+
+		if _, cleanupErr := cleanupJob(cleanupCtx, jobConfig.Cleanup.Args, globalConfig, a, logger); cleanupErr != nil {
+			logger.Warn("cleanup job failed", zap.String("type", jobConfig.Cleanup.Type), zap.Error(cleanupErr))
+		}
+	}()
+
+	return mainJob(ctx, jobConfig.Main.Args, globalConfig, a, logger)
+}
+
+// detachedContext keeps values of the parent context but is never cancelled
+type detachedContext struct{ context.Context }
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+func (detachedContext) Err() error { return nil }
+
 // "timeout" in config
 func timeoutJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (
 	data any, err error, //nolint:unparam // data is here to match Job
@@ -189,7 +253,11 @@ func loopJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig,
 		return nil, fmt.Errorf("error parsing job config: %w", err)
 	}
 
-	for jobConfig.Next(ctx) {
+	ctx = jobConfig.WatchActivity(ctx)
+
+	defer jobConfig.finishProgress()
+
+	for jobConfig.Next(ctx, a, logger) {
 		job := Get(jobConfig.Job.Type)
 		if job == nil {
 			return nil, fmt.Errorf("unknown job %q", jobConfig.Job.Type)
@@ -201,9 +269,174 @@ func loopJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig,
 		}
 
 		ctx = context.WithValue(ctx, templates.ContextKey("data."+jobConfig.Job.Name), data)
+		jobConfig.Heartbeat()
 	}
 
-	return nil, nil
+	return nil, jobConfig.ActivityErr()
+}
+
+// "checkpoint" in config
+func checkpointJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var jobConfig struct {
+		BasicJobConfig
+
+		CheckpointFile     string
+		CheckpointInterval time.Duration
+		StateKey           string
+		Job                config.Config
+	}
+
+	if err := ParseConfig(&jobConfig, args, *globalConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	if jobConfig.CheckpointFile == "" || jobConfig.StateKey == "" {
+		return nil, fmt.Errorf("checkpoint file and state key are required")
+	}
+
+	job := Get(jobConfig.Job.Type)
+	if job == nil {
+		return nil, fmt.Errorf("unknown job %q", jobConfig.Job.Type)
+	}
+
+	stateKey := templates.ContextKey(jobConfig.StateKey)
+
+	if state, err := readCheckpoint(jobConfig.CheckpointFile); err == nil {
+		logger.Info("restored state from checkpoint", zap.String("path", jobConfig.CheckpointFile))
+
+		ctx = context.WithValue(ctx, stateKey, state)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		logger.Warn("failed to read checkpoint", zap.String("path", jobConfig.CheckpointFile), zap.Error(err))
+	}
+
+	// ctx is captured by reference so that the latest state gets persisted
+	saveCheckpoint := func() {
+		if err := writeCheckpoint(jobConfig.CheckpointFile, ctx.Value(stateKey)); err != nil {
+			logger.Warn("failed to write checkpoint", zap.String("path", jobConfig.CheckpointFile), zap.Error(err))
+		}
+	}
+
+	defer saveCheckpoint()
+
+	lastCheckpoint := time.Now()
+
+	ctx = jobConfig.WatchActivity(ctx)
+
+	defer jobConfig.finishProgress()
+
+	for jobConfig.Next(ctx, a, logger) {
+		data, err := job(ctx, jobConfig.Job.Args, globalConfig, a, logger)
+		if err != nil {
+			return nil, fmt.Errorf("error running job: %w", err)
+		}
+
+		ctx = context.WithValue(ctx, templates.ContextKey("data."+jobConfig.Job.Name), data)
+		jobConfig.Heartbeat()
+
+		if time.Since(lastCheckpoint) >= jobConfig.CheckpointInterval {
+			saveCheckpoint()
+
+			lastCheckpoint = time.Now()
+		}
+	}
+
+	return nil, jobConfig.ActivityErr()
+}
+
+func readCheckpoint(path string) (state any, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(content, &state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func writeCheckpoint(path string, state any) error {
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return utils.WriteFileAtomic(path, content)
+}
+
+// "think-time" in config
+func thinkTimeJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var jobConfig struct {
+		BasicJobConfig
+
+		Distribution string // exponential (default), normal or uniform
+		Mean         time.Duration
+		StdDev       time.Duration
+		Job          config.Config
+	}
+
+	if err := ParseConfig(&jobConfig, args, *globalConfig); err != nil {
+		return nil, fmt.Errorf("error parsing job config: %w", err)
+	}
+
+	if _, err := thinkTime(jobConfig.Distribution, jobConfig.Mean, jobConfig.StdDev); err != nil {
+		return nil, err
+	}
+
+	job := Get(jobConfig.Job.Type)
+	if job == nil {
+		return nil, fmt.Errorf("unknown job %q", jobConfig.Job.Type)
+	}
+
+	ctx = jobConfig.WatchActivity(ctx)
+
+	defer jobConfig.finishProgress()
+
+	for jobConfig.Next(ctx, a, logger) {
+		data, err := job(ctx, jobConfig.Job.Args, globalConfig, a, logger)
+		if err != nil {
+			return nil, fmt.Errorf("error running job: %w", err)
+		}
+
+		ctx = context.WithValue(ctx, templates.ContextKey("data."+jobConfig.Job.Name), data)
+		jobConfig.Heartbeat()
+
+		delay, _ := thinkTime(jobConfig.Distribution, jobConfig.Mean, jobConfig.StdDev)
+		if !utils.Sleep(ctx, delay) {
+			break
+		}
+	}
+
+	return nil, jobConfig.ActivityErr()
+}
+
+// thinkTime samples a non-negative delay from the given distribution
+func thinkTime(distribution string, mean, stdDev time.Duration) (time.Duration, error) {
+	var delay float64
+
+	switch distribution {
+	case "", "exponential":
+		delay = rand.ExpFloat64() * float64(mean)
+	case "normal":
+		delay = rand.NormFloat64()*float64(stdDev) + float64(mean)
+	case "uniform":
+		// uniform distribution over [mean-w, mean+w] has standard deviation of w/sqrt(3)
+		const uniformVarianceFactor = 3
+
+		halfWidth := math.Sqrt(uniformVarianceFactor) * float64(stdDev)
+		delay = float64(mean) - halfWidth + rand.Float64()*2*halfWidth
+	default:
+		return 0, fmt.Errorf("unknown distribution %q", distribution)
+	}
+
+	return time.Duration(utils.Max(delay, 0)), nil
 }
 
 func lockJob(ctx context.Context, args config.Args, globalConfig *GlobalConfig, a *metrics.Accumulator, logger *zap.Logger) (data any, err error) {
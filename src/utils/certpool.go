@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+)
+
+// ClientCertConfig points to a PEM encoded client certificate and its private key
+type ClientCertConfig struct {
+	CertFile string
+	KeyFile  string
+}
+
+// RotatingCertPool holds a client certificate and reloads it in background when the files change
+// so that short-lived certificates can be rotated without restarting the process
+type RotatingCertPool struct {
+	config ClientCertConfig
+
+	mutex   sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// how often to check certificate files for changes
+const certCheckInterval = time.Second
+
+var certPools SyncMap[ClientCertConfig, *RotatingCertPool]
+
+// GetRotatingCertPool returns a shared pool for the given files loading the certificate if necessary.
+// Pools live as long as the process, every one of them watches its files until then.
+func GetRotatingCertPool(config ClientCertConfig) (*RotatingCertPool, error) {
+	if pool, ok := certPools.Load(config); ok {
+		return pool, nil
+	}
+
+	pool := &RotatingCertPool{config: config}
+	if err := pool.reload(); err != nil {
+		return nil, err
+	}
+
+	actual, loaded := certPools.LoadOrStore(config, pool)
+	if !loaded {
+		go pool.watch(certCheckInterval)
+	}
+
+	return actual, nil
+}
+
+// Certificate returns the most recent successfully loaded certificate
+func (p *RotatingCertPool) Certificate() *tls.Certificate {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.cert
+}
+
+// GetClientCertificate is meant to be used as tls.Config.GetClientCertificate
+func (p *RotatingCertPool) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return p.Certificate(), nil
+}
+
+// watch polls the files for changes, polling works the same for plain files and for the symlink swaps
+// kubernetes does when it updates mounted secrets
+func (p *RotatingCertPool) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// files may be mid-rotation, keep serving the previous certificate and retry on the next check
+		_ = p.reload()
+	}
+}
+
+// reload loads the certificate if any of the files has been modified since the last successful load
+func (p *RotatingCertPool) reload() error {
+	modTime, err := latestModTime(p.config.CertFile, p.config.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.RLock()
+	unchanged := p.cert != nil && modTime.Equal(p.modTime)
+	p.mutex.RUnlock()
+
+	if unchanged {
+		return nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(p.config.CertFile, p.config.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.cert, p.modTime = &cert, modTime
+
+	return nil
+}
+
+func latestModTime(paths ...string) (time.Time, error) {
+	var result time.Time
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(result) {
+			result = info.ModTime()
+		}
+	}
+
+	return result, nil
+}
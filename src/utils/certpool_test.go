@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestCert(t *testing.T, config ClientCertConfig, commonName string, modTime time.Time) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(config.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(config.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{config.CertFile, config.KeyFile} {
+		if err = os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func commonName(t *testing.T, cert *RotatingCertPool) string {
+	t.Helper()
+
+	parsed, err := x509.ParseCertificate(cert.Certificate().Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return parsed.Subject.CommonName
+}
+
+func TestRotatingCertPool(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	config := ClientCertConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+	now := time.Now()
+
+	writeTestCert(t, config, "first", now.Add(-time.Minute))
+
+	pool, err := GetRotatingCertPool(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name := commonName(t, pool); name != "first" {
+		t.Fatalf("unexpected certificate %q", name)
+	}
+
+	writeTestCert(t, config, "second", now)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for commonName(t, pool) != "second" {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate was not reloaded in time")
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+}
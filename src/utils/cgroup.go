@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	cgroupCPUMaxPath    = "/sys/fs/cgroup/cpu.max"
+	cgroupMemoryMaxPath = "/sys/fs/cgroup/memory.max"
+)
+
+// UpdateGOMAXPROCS lowers GOMAXPROCS to the cgroup v2 cpu quota of the container (rounded up) and returns the resulting value.
+// Explicitly set GOMAXPROCS environment variable takes precedence.
+func UpdateGOMAXPROCS() (int, error) {
+	current := runtime.GOMAXPROCS(0)
+
+	if _, ok := os.LookupEnv("GOMAXPROCS"); ok {
+		return current, nil
+	}
+
+	quota, err := cgroupCPUQuota(cgroupCPUMaxPath)
+	if err != nil || quota <= 0 {
+		return current, err
+	}
+
+	procs := int(math.Ceil(quota))
+	if procs >= current {
+		return current, nil
+	}
+
+	runtime.GOMAXPROCS(procs)
+
+	return procs, nil
+}
+
+// cgroupCPUQuota returns the amount of cpus available according to cpu.max or 0 if there's no limit
+func cgroupCPUQuota(path string) (float64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	fields := strings.Fields(string(content))
+	if len(fields) != 2 {
+		return 0, fmt.Errorf("unexpected %v format: %q", path, content)
+	}
+
+	if fields[0] == "max" {
+		return 0, nil
+	}
+
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+
+	period, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || period <= 0 {
+		return 0, fmt.Errorf("invalid cpu period %q", fields[1])
+	}
+
+	return quota / period, nil
+}
+
+// UpdateMemoryLimit sets the runtime soft memory limit to 90% of the cgroup v2 memory limit of the container
+// to leave some headroom for the OS and runtime metadata. Returns the limit or 0 if there's none.
+func UpdateMemoryLimit() (int64, error) {
+	const headroomFactor = 0.9
+
+	content, err := os.ReadFile(cgroupMemoryMaxPath)
+	if err != nil {
+		return 0, err
+	}
+
+	value := strings.TrimSpace(string(content))
+	if value == "max" {
+		return 0, nil
+	}
+
+	maxBytes, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	limit := int64(float64(maxBytes) * headroomFactor)
+
+	return limit, setMemoryLimit(limit)
+}
@@ -0,0 +1,70 @@
+package utils
+
+import "sync"
+
+// CircularBuffer is a fixed-size FIFO queue that evicts the oldest element when full. Safe for concurrent use.
+type CircularBuffer[T any] struct {
+	mutex sync.Mutex
+	items []T
+	start int
+	size  int
+}
+
+// NewCircularBuffer returns an empty buffer that holds at most capacity elements
+func NewCircularBuffer[T any](capacity int) *CircularBuffer[T] {
+	return &CircularBuffer[T]{items: make([]T, Max(capacity, 1))}
+}
+
+// Push adds an element evicting the oldest one if the buffer is full
+func (b *CircularBuffer[T]) Push(item T) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.items[(b.start+b.size)%len(b.items)] = item
+
+	if b.size < len(b.items) {
+		b.size++
+	} else {
+		b.start = (b.start + 1) % len(b.items)
+	}
+}
+
+// Pop removes and returns the oldest element
+func (b *CircularBuffer[T]) Pop() (T, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var zero T
+
+	if b.size == 0 {
+		return zero, false
+	}
+
+	item := b.items[b.start]
+	b.items[b.start] = zero
+	b.start = (b.start + 1) % len(b.items)
+	b.size--
+
+	return item, true
+}
+
+// Slice returns a copy of all elements, oldest first
+func (b *CircularBuffer[T]) Slice() []T {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	result := make([]T, b.size)
+	for i := range result {
+		result[i] = b.items[(b.start+i)%len(b.items)]
+	}
+
+	return result
+}
+
+// Len returns the amount of elements in the buffer
+func (b *CircularBuffer[T]) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.size
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCircularBuffer(t *testing.T) {
+	t.Parallel()
+
+	buffer := NewCircularBuffer[int](3)
+
+	for i := 1; i <= 4; i++ {
+		buffer.Push(i)
+	}
+
+	if got := buffer.Slice(); !reflect.DeepEqual(got, []int{2, 3, 4}) {
+		t.Fatalf("unexpected contents: %v", got)
+	}
+
+	if item, ok := buffer.Pop(); !ok || item != 2 {
+		t.Errorf("expected to pop 2, got %v (%v)", item, ok)
+	}
+
+	buffer.Push(5)
+
+	if got := buffer.Slice(); !reflect.DeepEqual(got, []int{3, 4, 5}) || buffer.Len() != 3 {
+		t.Errorf("unexpected contents after pop: %v", got)
+	}
+
+	for buffer.Len() > 0 {
+		buffer.Pop()
+	}
+
+	if _, ok := buffer.Pop(); ok {
+		t.Error("expected pop from an empty buffer to fail")
+	}
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it over path
+// so that readers never observe a partially written file even if the process crashes mid-write
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
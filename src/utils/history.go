@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// JobHistoryEntry describes a single finished job instance
+type JobHistoryEntry struct {
+	Timestamp time.Time     `json:"timestamp"`
+	JobName   string        `json:"job_name"`
+	JobType   string        `json:"job_type"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// JobHistory keeps the last Capacity job results. Safe for concurrent use.
+type JobHistory struct {
+	entries *CircularBuffer[JobHistoryEntry]
+}
+
+// NewJobHistory returns an empty JobHistory that holds at most capacity entries
+func NewJobHistory(capacity int) *JobHistory {
+	return &JobHistory{entries: NewCircularBuffer[JobHistoryEntry](capacity)}
+}
+
+// Push adds an entry evicting the oldest one if the history is full
+func (h *JobHistory) Push(entry JobHistoryEntry) {
+	h.entries.Push(entry)
+}
+
+// Entries returns up to limit most recent entries for the given job name, newest first.
+// Empty jobName matches all jobs and non-positive limit means no limit.
+func (h *JobHistory) Entries(jobName string, limit int) []JobHistoryEntry {
+	entries := h.entries.Slice()
+	result := make([]JobHistoryEntry, 0, len(entries))
+
+	for i := len(entries) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		if jobName == "" || entries[i].JobName == jobName {
+			result = append(result, entries[i])
+		}
+	}
+
+	return result
+}
+
+// ServeHTTP responds with history entries as json, supports optional "job" and "limit" query parameters
+func (h *JobHistory) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var limit int
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		var err error
+		if limit, err = strconv.Atoi(rawLimit); err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(h.Entries(query.Get("job"), limit)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
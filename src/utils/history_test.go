@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestJobHistoryEviction(t *testing.T) {
+	t.Parallel()
+
+	const capacity = 3
+
+	history := NewJobHistory(capacity)
+
+	for i := 0; i < 5; i++ {
+		history.Push(JobHistoryEntry{JobName: strconv.Itoa(i % 2), JobType: strconv.Itoa(i)})
+	}
+
+	entries := history.Entries("", 0)
+	if len(entries) != capacity {
+		t.Fatalf("expected %d entries, got %d", capacity, len(entries))
+	}
+
+	for i, expected := range []string{"4", "3", "2"} {
+		if entries[i].JobType != expected {
+			t.Errorf("entry %d: expected %s, got %s", i, expected, entries[i].JobType)
+		}
+	}
+
+	if filtered := history.Entries("0", 1); len(filtered) != 1 || filtered[0].JobType != "4" {
+		t.Errorf("unexpected filtered entries: %+v", filtered)
+	}
+}
@@ -0,0 +1,294 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EvalJSONLogic evaluates a JSON Logic (https://jsonlogic.com) rule and returns whether the result is truthy.
+// Variables referenced with {"var": "name"} are resolved with lookup, dotted names walk into nested maps.
+// Supported operators: var, ==, ===, !=, !==, <, <=, >, >=, !, !!, and, or, in, if
+func EvalJSONLogic(rule string, lookup func(name string) any) (bool, error) {
+	var parsed any
+	if err := json.Unmarshal([]byte(rule), &parsed); err != nil {
+		return false, fmt.Errorf("error parsing json logic rule: %w", err)
+	}
+
+	result, err := applyJSONLogic(parsed, lookup)
+	if err != nil {
+		return false, err
+	}
+
+	return jsonLogicTruthy(result), nil
+}
+
+func applyJSONLogic(rule any, lookup func(string) any) (any, error) {
+	switch rule := rule.(type) {
+	case []any:
+		result := make([]any, 0, len(rule))
+
+		for _, item := range rule {
+			value, err := applyJSONLogic(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+
+			result = append(result, value)
+		}
+
+		return result, nil
+	case map[string]any:
+		if len(rule) != 1 {
+			return nil, fmt.Errorf("json logic operation must have exactly one key, got %d", len(rule))
+		}
+
+		for op, rawArgs := range rule {
+			args, ok := rawArgs.([]any)
+			if !ok {
+				args = []any{rawArgs}
+			}
+
+			return applyJSONLogicOp(op, args, lookup)
+		}
+	}
+
+	return rule, nil
+}
+
+func applyJSONLogicOp(op string, args []any, lookup func(string) any) (any, error) {
+	// these operators evaluate their arguments lazily
+	switch op {
+	case "and", "or":
+		var value any
+
+		for _, arg := range args {
+			var err error
+			if value, err = applyJSONLogic(arg, lookup); err != nil {
+				return nil, err
+			}
+
+			if jsonLogicTruthy(value) != (op == "and") {
+				return value, nil
+			}
+		}
+
+		return value, nil
+	case "if":
+		for i := 0; i+1 < len(args); i += 2 {
+			cond, err := applyJSONLogic(args[i], lookup)
+			if err != nil {
+				return nil, err
+			}
+
+			if jsonLogicTruthy(cond) {
+				return applyJSONLogic(args[i+1], lookup)
+			}
+		}
+
+		if len(args)%2 == 1 {
+			return applyJSONLogic(args[len(args)-1], lookup)
+		}
+
+		return nil, nil
+	}
+
+	values := make([]any, len(args))
+
+	for i, arg := range args {
+		var err error
+		if values[i], err = applyJSONLogic(arg, lookup); err != nil {
+			return nil, err
+		}
+	}
+
+	arg := func(i int) any {
+		if i < len(values) {
+			return values[i]
+		}
+
+		return nil
+	}
+
+	switch op {
+	case "var":
+		return jsonLogicVar(fmt.Sprint(arg(0)), arg(1), lookup), nil
+	case "==":
+		return jsonLogicLooseEqual(arg(0), arg(1)), nil
+	case "!=":
+		return !jsonLogicLooseEqual(arg(0), arg(1)), nil
+	case "===":
+		return jsonLogicStrictEqual(arg(0), arg(1)), nil
+	case "!==":
+		return !jsonLogicStrictEqual(arg(0), arg(1)), nil
+	case "<", "<=", ">", ">=":
+		return jsonLogicCompare(op, values)
+	case "!":
+		return !jsonLogicTruthy(arg(0)), nil
+	case "!!":
+		return jsonLogicTruthy(arg(0)), nil
+	case "in":
+		return jsonLogicIn(arg(0), arg(1)), nil
+	default:
+		return nil, fmt.Errorf("unsupported json logic operator %q", op)
+	}
+}
+
+func jsonLogicVar(name string, fallback any, lookup func(string) any) any {
+	if value := lookup(name); value != nil {
+		return value
+	}
+
+	path := strings.Split(name, ".")
+
+	value := lookup(path[0])
+	for _, key := range path[1:] {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return fallback
+		}
+
+		value = m[key]
+	}
+
+	if value == nil {
+		return fallback
+	}
+
+	return value
+}
+
+func jsonLogicTruthy(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	}
+
+	if n, ok := jsonLogicNumber(value, false); ok {
+		return n != 0
+	}
+
+	return true
+}
+
+// jsonLogicNumber converts numeric values to float64, numeric strings are only converted when parseStrings is set
+func jsonLogicNumber(value any, parseStrings bool) (float64, bool) {
+	switch v := value.(type) {
+	case string:
+		if !parseStrings {
+			return 0, false
+		}
+
+		n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+
+		return n, err == nil
+	case bool:
+		return 0, false
+	}
+
+	rv := reflect.ValueOf(value)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func jsonLogicLooseEqual(a, b any) bool {
+	_, aIsString := a.(string)
+	_, bIsString := b.(string)
+
+	if !aIsString || !bIsString {
+		an, aok := jsonLogicNumber(a, true)
+		bn, bok := jsonLogicNumber(b, true)
+
+		if aok && bok {
+			return an == bn
+		}
+	}
+
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func jsonLogicStrictEqual(a, b any) bool {
+	an, aok := jsonLogicNumber(a, false)
+	bn, bok := jsonLogicNumber(b, false)
+
+	if aok || bok {
+		return aok && bok && an == bn
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// jsonLogicCompare also supports the "between" form with three arguments for < and <=
+func jsonLogicCompare(op string, values []any) (bool, error) {
+	const minArgs, maxArgs = 2, 3
+
+	if len(values) < minArgs || len(values) > maxArgs || (len(values) == maxArgs && op != "<" && op != "<=") {
+		return false, fmt.Errorf("invalid number of arguments for %q: %d", op, len(values))
+	}
+
+	numbers := make([]float64, len(values))
+
+	for i, value := range values {
+		n, ok := jsonLogicNumber(value, true)
+		if !ok {
+			return false, nil
+		}
+
+		numbers[i] = n
+	}
+
+	for i := 0; i+1 < len(numbers); i++ {
+		a, b := numbers[i], numbers[i+1]
+
+		var ok bool
+
+		switch op {
+		case "<":
+			ok = a < b
+		case "<=":
+			ok = a <= b
+		case ">":
+			ok = a > b
+		case ">=":
+			ok = a >= b
+		}
+
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func jsonLogicIn(needle, haystack any) bool {
+	switch h := haystack.(type) {
+	case string:
+		return strings.Contains(h, fmt.Sprint(needle))
+	case []any:
+		for _, item := range h {
+			if jsonLogicLooseEqual(needle, item) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,53 @@
+package utils
+
+import "testing"
+
+func TestEvalJSONLogic(t *testing.T) {
+	t.Parallel()
+
+	data := map[string]any{
+		"goos":    "linux",
+		"version": "0.8.30",
+		"cpus":    8,
+		"env":     map[string]any{"region": "eu", "canary": true},
+	}
+	lookup := func(name string) any { return data[name] }
+
+	testCases := []struct {
+		rule     string
+		expected bool
+	}{
+		{rule: `{"==": [{"var": "goos"}, "linux"]}`, expected: true},
+		{rule: `{"!=": [{"var": "goos"}, "linux"]}`, expected: false},
+		{rule: `{"==": [{"var": "cpus"}, "8"]}`, expected: true},
+		{rule: `{"===": [{"var": "cpus"}, "8"]}`, expected: false},
+		{rule: `{">=": [{"var": "cpus"}, 4]}`, expected: true},
+		{rule: `{"<": [{"var": "cpus"}, 4]}`, expected: false},
+		{rule: `{"<=": [1, {"var": "cpus"}, 10]}`, expected: true},
+		{rule: `{"<": [1, {"var": "cpus"}, 8]}`, expected: false},
+		{rule: `{"and": [{"==": [{"var": "goos"}, "linux"]}, {">": [{"var": "cpus"}, 2]}]}`, expected: true},
+		{rule: `{"and": [{"==": [{"var": "goos"}, "linux"]}, {">": [{"var": "cpus"}, 16]}]}`, expected: false},
+		{rule: `{"or": [{"==": [{"var": "goos"}, "windows"]}, {"var": "env.canary"}]}`, expected: true},
+		{rule: `{"or": [{"==": [{"var": "goos"}, "windows"]}, {"var": "missing"}]}`, expected: false},
+		{rule: `{"in": [{"var": "env.region"}, ["eu", "us"]]}`, expected: true},
+		{rule: `{"in": [{"var": "goos"}, ["darwin", "windows"]]}`, expected: false},
+		{rule: `{"in": ["0.8", {"var": "version"}]}`, expected: true},
+		{rule: `{"!": {"var": "missing"}}`, expected: true},
+		{rule: `{"if": [{"var": "env.canary"}, {"var": ["missing", "fallback"]}, false]}`, expected: true},
+	}
+
+	for _, tc := range testCases {
+		result, err := EvalJSONLogic(tc.rule, lookup)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.rule, err)
+		} else if result != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.rule, tc.expected, result)
+		}
+	}
+
+	for _, rule := range []string{`{"==": [1, 1]`, `{"unknown": [1]}`, `{"==": [1, 1], "!=": [1, 2]}`} {
+		if _, err := EvalJSONLogic(rule, lookup); err == nil {
+			t.Errorf("%s: expected an error", rule)
+		}
+	}
+}
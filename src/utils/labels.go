@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type labelOperator int
+
+const (
+	labelEquals labelOperator = iota
+	labelNotEquals
+	labelIn
+	labelNotIn
+	labelExists
+	labelDoesNotExist
+)
+
+type labelRequirement struct {
+	key      string
+	operator labelOperator
+	values   []string
+}
+
+// LabelSelector is a parsed kubernetes-style label selector, all requirements have to match
+type LabelSelector []labelRequirement
+
+var (
+	setRequirementRegexp = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+	labelKeyRegexp       = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_./]*[A-Za-z0-9])?$`)
+)
+
+// ParseLabelSelector parses selectors like "env=prod,tier!=cache,region notin (eu,ap),canary,!legacy"
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var result LabelSelector
+
+	for _, raw := range splitOutsideParens(selector) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		requirement, err := parseLabelRequirement(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, requirement)
+	}
+
+	return result, nil
+}
+
+func parseLabelRequirement(raw string) (labelRequirement, error) {
+	var requirement labelRequirement
+
+	switch match := setRequirementRegexp.FindStringSubmatch(raw); {
+	case match != nil:
+		requirement.key, requirement.operator = match[1], labelIn
+		if match[2] == "notin" {
+			requirement.operator = labelNotIn
+		}
+
+		for _, value := range strings.Split(match[3], ",") {
+			requirement.values = append(requirement.values, strings.TrimSpace(value))
+		}
+	case strings.HasPrefix(raw, "!"):
+		requirement.key, requirement.operator = strings.TrimSpace(raw[1:]), labelDoesNotExist
+	case strings.Contains(raw, "!="):
+		key, value, _ := strings.Cut(raw, "!=")
+		requirement = labelRequirement{key: strings.TrimSpace(key), operator: labelNotEquals, values: []string{strings.TrimSpace(value)}}
+	case strings.Contains(raw, "="):
+		key, value, _ := strings.Cut(raw, "=")
+		requirement = labelRequirement{key: strings.TrimSpace(key), operator: labelEquals, values: []string{strings.TrimSpace(strings.TrimPrefix(value, "="))}}
+	default:
+		requirement.key, requirement.operator = raw, labelExists
+	}
+
+	if !labelKeyRegexp.MatchString(requirement.key) {
+		return requirement, fmt.Errorf("invalid label key in selector requirement %q", raw)
+	}
+
+	return requirement, nil
+}
+
+func splitOutsideParens(s string) []string {
+	var (
+		result []string
+		depth  int
+		start  int
+	)
+
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				result = append(result, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(result, s[start:])
+}
+
+// Matches reports whether the labels satisfy all requirements of the selector, empty selector matches everything
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, requirement := range s {
+		if !requirement.matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r labelRequirement) matches(labels map[string]string) bool {
+	value, exists := labels[r.key]
+
+	switch r.operator {
+	case labelEquals:
+		return exists && value == r.values[0]
+	case labelNotEquals:
+		return !exists || value != r.values[0]
+	case labelIn:
+		return exists && contains(r.values, value)
+	case labelNotIn:
+		return !exists || !contains(r.values, value)
+	case labelExists:
+		return exists
+	case labelDoesNotExist:
+		return !exists
+	default:
+		return false
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
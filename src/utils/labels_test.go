@@ -0,0 +1,42 @@
+package utils
+
+import "testing"
+
+func TestLabelSelector(t *testing.T) {
+	t.Parallel()
+
+	labels := map[string]string{"env": "prod", "region": "us", "canary": "true"}
+
+	testCases := []struct {
+		selector string
+		expected bool
+	}{
+		{selector: "", expected: true},
+		{selector: "env=prod", expected: true},
+		{selector: "env==prod,region=us", expected: true},
+		{selector: "env!=prod", expected: false},
+		{selector: "env=prod,region notin (eu,ap)", expected: true},
+		{selector: "region in (eu, ap)", expected: false},
+		{selector: "canary", expected: true},
+		{selector: "!legacy,env in (prod,staging)", expected: true},
+		{selector: "!canary", expected: false},
+		{selector: "tier!=cache", expected: true},
+	}
+
+	for _, tc := range testCases {
+		selector, err := ParseLabelSelector(tc.selector)
+		if err != nil {
+			t.Errorf("failed to parse %q: %v", tc.selector, err)
+
+			continue
+		}
+
+		if got := selector.Matches(labels); got != tc.expected {
+			t.Errorf("%q: expected %v, got %v", tc.selector, tc.expected, got)
+		}
+	}
+
+	if _, err := ParseLabelSelector("env in (prod"); err == nil {
+		t.Error("expected an error for an unbalanced selector")
+	}
+}
@@ -3,18 +3,12 @@ package utils
 import "sync"
 
 type Locker struct {
-	mutexes sync.Map // Zero value is empty and ready for use
+	mutexes SyncMap[string, *sync.Mutex] // Zero value is empty and ready for use
 }
 
 func (m *Locker) Lock(key string) func() {
-	value, _ := m.mutexes.LoadOrStore(key, &sync.Mutex{})
+	mtx, _ := m.mutexes.LoadOrStore(key, &sync.Mutex{})
+	mtx.Lock()
 
-	mtx, ok := value.(*sync.Mutex)
-	if ok {
-		mtx.Lock()
-
-		return func() { mtx.Unlock() }
-	}
-
-	return func() {}
+	return func() { mtx.Unlock() }
 }
@@ -0,0 +1,43 @@
+package utils
+
+import "sort"
+
+// Ordered is a constraint for types that support < operator
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr | ~float32 | ~float64 | ~string
+}
+
+// Keys returns keys of the map in ascending order
+func Keys[K Ordered, V any](m map[K]V) []K {
+	result := make([]K, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	return result
+}
+
+// Values returns values of the map ordered by their keys
+func Values[K Ordered, V any](m map[K]V) []V {
+	result := make([]V, 0, len(m))
+	for _, k := range Keys(m) {
+		result = append(result, m[k])
+	}
+
+	return result
+}
+
+// Merge returns a new map with all key-value pairs from maps, later maps win on conflicts
+func Merge[K comparable, V any](maps ...map[K]V) map[K]V {
+	result := make(map[K]V)
+
+	for _, m := range maps {
+		for k, v := range m {
+			result[k] = v
+		}
+	}
+
+	return result
+}
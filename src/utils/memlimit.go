@@ -0,0 +1,12 @@
+//go:build go1.19
+// +build go1.19
+
+package utils
+
+import "runtime/debug"
+
+func setMemoryLimit(limit int64) error {
+	debug.SetMemoryLimit(limit)
+
+	return nil
+}
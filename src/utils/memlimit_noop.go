@@ -0,0 +1,10 @@
+//go:build !go1.19
+// +build !go1.19
+
+package utils
+
+import "errors"
+
+func setMemoryLimit(limit int64) error {
+	return errors.New("soft memory limit requires go1.19 or newer")
+}
@@ -1,11 +1,14 @@
 package metrics
 
+import "time"
+
 // Accumulator for statistical metrics for use in a single job. Requires Flush()-ing to Reporter.
 // Not concurrency-safe.
 type Accumulator struct {
 	jobID   string
 	stats   [NumStats]map[string]uint64 // Array of metrics by Stat. Each metric is a map of uint64 values by target.
 	metrics *Metrics
+	health  *healthTracker
 }
 
 type dimensions struct {
@@ -20,6 +23,25 @@ func (a *Accumulator) Add(target string, s Stat, n uint64) *Accumulator {
 	return a
 }
 
+// Requests returns the amount of requests attempted through this accumulator across all targets
+func (a *Accumulator) Requests() uint64 {
+	if a == nil {
+		return 0
+	}
+
+	return a.sum(RequestsAttemptedStat)
+}
+
+// HealthScore returns the share of attempted requests that got a response over the last minute, from 0 to 100.
+// Unlike the rest of the Accumulator it's safe to call concurrently with the owning job.
+func (a *Accumulator) HealthScore() float64 {
+	if a == nil {
+		return maxHealthScore
+	}
+
+	return a.health.score(time.Now())
+}
+
 // Inc increases Accumulator Stat value by 1. Returns self for chaining.
 func (a *Accumulator) Inc(target string, s Stat) *Accumulator { return a.Add(target, s, 1) }
 
@@ -30,6 +52,17 @@ func (a *Accumulator) Flush() {
 			a.metrics[stat].Store(dimensions{jobID: a.jobID, target: target}, value)
 		}
 	}
+
+	a.health.record(time.Now(), a.sum(RequestsAttemptedStat), a.sum(ResponsesReceivedStat))
+}
+
+func (a *Accumulator) sum(s Stat) uint64 {
+	var res uint64
+	for _, n := range a.stats[s] {
+		res += n
+	}
+
+	return res
 }
 
 // Clone a new, blank metrics Accumulator with the same Reporter as the original.
@@ -45,6 +78,7 @@ func newAccumulator(jobID string, data *Metrics) *Accumulator {
 	res := &Accumulator{
 		jobID:   jobID,
 		metrics: data,
+		health:  newHealthTracker(),
 	}
 
 	for s := RequestsAttemptedStat; s < NumStats; s++ {
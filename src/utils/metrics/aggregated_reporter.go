@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// NamedAccumulator is an Accumulator that's reported under its own name by AggregatedReporter
+type NamedAccumulator struct {
+	Name string
+	*Accumulator
+}
+
+// AggregatedReporter reports stats of each named accumulator along with their aggregate total.
+// Only flushed values are taken into account so the accumulators can keep being used by their jobs.
+type AggregatedReporter struct {
+	logger *zap.Logger
+
+	mutex        sync.Mutex
+	accumulators []NamedAccumulator
+	reported     map[string]Stats // values already added to prometheus counters
+}
+
+// NewAggregatedReporter creates a new Reporter for the given accumulators
+func NewAggregatedReporter(logger *zap.Logger, accumulators []NamedAccumulator) *AggregatedReporter {
+	return &AggregatedReporter{logger: logger, accumulators: accumulators, reported: make(map[string]Stats)}
+}
+
+// Add an accumulator to be reported, safe to call while the reporter is in use
+func (r *AggregatedReporter) Add(acc NamedAccumulator) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.accumulators = append(r.accumulators, acc)
+}
+
+// Summary returns flushed stats of every accumulator by name and their sum.
+// Accumulators sharing a name are merged.
+func (r *AggregatedReporter) Summary(tracker *StatsTracker) (perAccumulator PerTargetStats, total Stats) {
+	r.mutex.Lock()
+	accumulators := r.accumulators
+	r.mutex.Unlock()
+
+	perAccumulator = make(PerTargetStats, len(accumulators))
+
+	for _, acc := range accumulators {
+		stats := tracker.metrics.SumJob(acc.jobID)
+
+		for s := RequestsAttemptedStat; s < NumStats; s++ {
+			merged := perAccumulator[acc.Name]
+			merged[s] += stats[s]
+			perAccumulator[acc.Name] = merged
+
+			total[s] += stats[s]
+		}
+	}
+
+	return perAccumulator, total
+}
+
+// WriteSummary logs the summary and adds what's changed since the last call to prometheus counters
+func (r *AggregatedReporter) WriteSummary(tracker *StatsTracker) {
+	perAccumulator, total := r.Summary(tracker)
+	if len(perAccumulator) == 0 {
+		return
+	}
+
+	r.logger.Info("aggregated stats", zap.Object("total", &total), zap.Object("accumulators", perAccumulator))
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for name, stats := range perAccumulator {
+		addAccumulatorStats(name, Diff(stats, r.reported[name]))
+		r.reported[name] = stats
+	}
+}
+
+// addAccumulatorStats adds increments of accumulator stats to prometheus counters
+func addAccumulatorStats(name string, stats Stats) {
+	if accumulatorCounter == nil {
+		return
+	}
+
+	for s := RequestsAttemptedStat; s < NumStats; s++ {
+		if stats[s] > 0 {
+			accumulatorCounter.With(prometheus.Labels{AccumulatorLabel: name, StatLabel: statNames[s]}).Add(float64(stats[s]))
+		}
+	}
+}
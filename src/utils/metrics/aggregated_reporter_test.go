@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestAggregatedReporterSummary(t *testing.T) {
+	t.Parallel()
+
+	var data Metrics
+
+	first, second, ignored := data.NewAccumulator("first"), data.NewAccumulator("second"), data.NewAccumulator("ignored")
+
+	first.Add("tcp://a", RequestsAttemptedStat, 3).Add("tcp://b", RequestsAttemptedStat, 4).Add("tcp://a", BytesSentStat, 100).Flush()
+	second.Add("tcp://a", RequestsAttemptedStat, 5).Add("tcp://a", BytesSentStat, 50).Flush()
+	ignored.Add("tcp://a", RequestsAttemptedStat, 1000).Flush()
+
+	reporter := NewAggregatedReporter(zap.NewNop(), []NamedAccumulator{{Name: "first", Accumulator: first}, {Name: "second", Accumulator: second}})
+	perAccumulator, total := reporter.Summary(NewStatsTracker(&data))
+
+	if got := perAccumulator["first"]; got[RequestsAttemptedStat] != 7 || got[BytesSentStat] != 100 {
+		t.Errorf("unexpected stats of the first accumulator %v", got)
+	}
+
+	if got := perAccumulator["second"]; got[RequestsAttemptedStat] != 5 || got[BytesSentStat] != 50 {
+		t.Errorf("unexpected stats of the second accumulator %v", got)
+	}
+
+	if total[RequestsAttemptedStat] != 12 || total[BytesSentStat] != 150 {
+		t.Errorf("unexpected aggregate %v", total)
+	}
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/Arriven/db1000n/src/utils"
+)
+
+const (
+	healthWindow         = time.Minute
+	healthSampleInterval = time.Second
+	maxHealthScore       = 100
+)
+
+type healthSample struct {
+	timestamp time.Time
+	attempted uint64
+	received  uint64
+}
+
+// healthTracker keeps periodic snapshots of request counters to compute a rolling success rate.
+// Recording is done by the owning job while reading is safe from any goroutine.
+type healthTracker struct {
+	samples    *utils.CircularBuffer[healthSample]
+	lastSample time.Time
+}
+
+func newHealthTracker() *healthTracker {
+	return &healthTracker{samples: utils.NewCircularBuffer[healthSample](int(healthWindow/healthSampleInterval) + 1)}
+}
+
+func (h *healthTracker) record(now time.Time, attempted, received uint64) {
+	if now.Sub(h.lastSample) < healthSampleInterval {
+		return
+	}
+
+	h.lastSample = now
+	h.samples.Push(healthSample{timestamp: now, attempted: attempted, received: received})
+}
+
+// score returns percentage of attempted requests that got a response within the window, 100 if there were no attempts
+func (h *healthTracker) score(now time.Time) float64 {
+	samples := h.samples.Slice()
+	if len(samples) == 0 {
+		return maxHealthScore
+	}
+
+	latest, oldest := samples[len(samples)-1], healthSample{}
+
+	for _, sample := range samples {
+		if now.Sub(sample.timestamp) <= healthWindow {
+			break
+		}
+
+		oldest = sample
+	}
+
+	attempted, received := latest.attempted-oldest.attempted, latest.received-oldest.received
+	if attempted == 0 {
+		return maxHealthScore
+	}
+
+	if received > attempted {
+		received = attempted
+	}
+
+	return maxHealthScore * float64(received) / float64(attempted)
+}
@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthScore(t *testing.T) {
+	t.Parallel()
+
+	h := newHealthTracker()
+	start := time.Now()
+
+	if score := h.score(start); score != maxHealthScore {
+		t.Errorf("expected %v without samples, got %v", maxHealthScore, score)
+	}
+
+	// a minute of failures followed by a minute of successes
+	h.record(start, 100, 0)
+	h.record(start.Add(healthWindow), 200, 100)
+
+	if score := h.score(start.Add(healthWindow)); score != 50 {
+		t.Errorf("expected 50 across the whole window, got %v", score)
+	}
+
+	if score := h.score(start.Add(healthWindow + time.Second)); score != maxHealthScore {
+		t.Errorf("expected failures to fall out of the window, got %v", score)
+	}
+}
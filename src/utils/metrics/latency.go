@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	latencyBucketWidth = 10 * time.Millisecond
+	latencyBuckets     = 100
+)
+
+// LatencyHistogram counts response times in 10ms buckets from 0 to 1s plus an overflow bucket.
+// It's much cheaper than a precise histogram and good enough to spot multimodal latency. Not concurrency-safe.
+type LatencyHistogram struct {
+	buckets [latencyBuckets + 1]int
+}
+
+// Observe adds a single response time to the histogram
+func (h *LatencyHistogram) Observe(latency time.Duration) {
+	bucket := int(latency / latencyBucketWidth)
+	if bucket < 0 {
+		bucket = 0
+	} else if bucket > latencyBuckets {
+		bucket = latencyBuckets
+	}
+
+	h.buckets[bucket]++
+}
+
+// Heatmap returns counts of non-empty buckets keyed by their range, e.g. "10-20ms" or "1000ms+"
+func (h *LatencyHistogram) Heatmap() map[string]int {
+	res := make(map[string]int)
+
+	for i, count := range h.buckets {
+		if count == 0 {
+			continue
+		}
+
+		lower := time.Duration(i) * latencyBucketWidth / time.Millisecond
+		if i == latencyBuckets {
+			res[fmt.Sprintf("%dms+", lower)] = count
+		} else {
+			res[fmt.Sprintf("%d-%dms", lower, lower+latencyBucketWidth/time.Millisecond)] = count
+		}
+	}
+
+	return res
+}
+
+// Reset clears all buckets
+func (h *LatencyHistogram) Reset() { h.buckets = [latencyBuckets + 1]int{} }
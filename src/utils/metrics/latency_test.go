@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	t.Parallel()
+
+	var h LatencyHistogram
+
+	for _, latency := range []time.Duration{0, 9 * time.Millisecond, 10 * time.Millisecond, 995 * time.Millisecond, time.Second, time.Minute} {
+		h.Observe(latency)
+	}
+
+	expected := map[string]int{"0-10ms": 2, "10-20ms": 1, "990-1000ms": 1, "1000ms+": 2}
+	if got := h.Heatmap(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	h.Reset()
+
+	if got := h.Heatmap(); len(got) != 0 {
+		t.Errorf("expected empty heatmap after reset, got %v", got)
+	}
+}
@@ -25,10 +25,11 @@ package metrics
 
 import (
 	"strings"
-	"sync"
+
+	"github.com/Arriven/db1000n/src/utils"
 )
 
-type Metrics [NumStats]sync.Map // Array of metrics by Stat. Each metric is a map of uint64 values by dimensions.
+type Metrics [NumStats]utils.SyncMap[dimensions, uint64] // Array of metrics by Stat. Each metric is a map of uint64 values by dimensions.
 
 // NewAccumulator returns a new metrics Accumulator for the Reporter.
 func (m *Metrics) NewAccumulator(jobID string) *Accumulator {
@@ -54,12 +55,7 @@ func (m *Metrics) SumAllStats(groupTargets bool) (stats PerTargetStats, totals S
 func (m *Metrics) Sum(s Stat) uint64 {
 	var res uint64
 
-	m[s].Range(func(_, v any) bool {
-		value, ok := v.(uint64)
-		if !ok {
-			return true
-		}
-
+	m[s].Range(func(_ dimensions, value uint64) bool {
 		res += value
 
 		return true
@@ -68,22 +64,29 @@ func (m *Metrics) Sum(s Stat) uint64 {
 	return res
 }
 
-// Returns a total sum of all metrics by target.
-func (m *Metrics) sumAllStatsByTarget(groupTargets bool) PerTargetStats {
-	res := make(PerTargetStats)
+// SumJob returns stats flushed by the accumulator with the given job id across all targets
+func (m *Metrics) SumJob(jobID string) Stats {
+	var res Stats
 
 	for s := RequestsAttemptedStat; s < NumStats; s++ {
-		m[s].Range(func(k, v any) bool {
-			d, ok := k.(dimensions)
-			if !ok {
-				return true
+		m[s].Range(func(d dimensions, value uint64) bool {
+			if d.jobID == jobID {
+				res[s] += value
 			}
 
-			value, ok := v.(uint64)
-			if !ok {
-				return true
-			}
+			return true
+		})
+	}
 
+	return res
+}
+
+// Returns a total sum of all metrics by target.
+func (m *Metrics) sumAllStatsByTarget(groupTargets bool) PerTargetStats {
+	res := make(PerTargetStats)
+
+	for s := RequestsAttemptedStat; s < NumStats; s++ {
+		m[s].Range(func(d dimensions, value uint64) bool {
 			var target string
 			if groupTargets {
 				protocol, _, found := strings.Cut(d.target, "://")
@@ -77,6 +77,12 @@ const (
 	CountryLabel  = `country`
 )
 
+// Aggregated reporter related values and labels
+const (
+	AccumulatorLabel = `accumulator`
+	StatLabel        = `stat`
+)
+
 // registered metrics
 var (
 	dnsBlastCounter  *prometheus.CounterVec
@@ -85,6 +91,8 @@ var (
 	slowlorisCounter *prometheus.CounterVec
 	rawnetCounter    *prometheus.CounterVec
 	clientCounter    *prometheus.CounterVec
+
+	accumulatorCounter *prometheus.CounterVec
 )
 
 // NewOptionsWithFlags returns metrics options initialized with command line flags.
@@ -103,6 +111,32 @@ func InitOrFail(ctx context.Context, logger *zap.Logger, prometheusOn bool, prom
 	}
 }
 
+// counterDefinition describes a prometheus counter so that it can be both created and listed in the schema
+type counterDefinition struct {
+	counter **prometheus.CounterVec
+	name    string
+	help    string
+	labels  []string
+}
+
+func counterDefinitions() []counterDefinition {
+	return []counterDefinition{
+		{&dnsBlastCounter, "db1000n_dns_blast_total", "Number of dns queries",
+			[]string{DNSBlastRootDomainLabel, DNSBlastSeedDomainLabel, DNSBlastProtocolLabel, StatusLabel}},
+		{&httpCounter, "db1000n_http_request_total", "Number of http queries",
+			[]string{HTTPDestinationHostLabel, HTTPMethodLabel, StatusLabel}},
+		{&packetgenCounter, "db1000n_packetgen_total", "Number of packet generation transfers",
+			[]string{PacketgenHostLabel, PacketgenDstHostPortLabel, PacketgenProtocolLabel, StatusLabel}},
+		{&slowlorisCounter, "db1000n_slowloris_total", "Number of sent raw tcp/udp packets",
+			[]string{SlowlorisAddressLabel, SlowlorisProtocolLabel, StatusLabel}},
+		{&rawnetCounter, "db1000n_rawnet_total", "Number of sent raw tcp/udp packets",
+			[]string{RawnetAddressLabel, RawnetProtocolLabel, StatusLabel}},
+		{&clientCounter, "db1000n_client_total", "Number of clients", []string{}},
+		{&accumulatorCounter, "db1000n_accumulator_stats_total", "Stats of named accumulators reported by the aggregated reporter",
+			[]string{AccumulatorLabel, StatLabel}},
+	}
+}
+
 // Init prometheus counters.
 func Init(clientID, country string) {
 	constLabels := prometheus.Labels{}
@@ -110,50 +144,40 @@ func Init(clientID, country string) {
 		constLabels[CountryLabel] = country
 	}
 
-	dnsBlastCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name:        "db1000n_dns_blast_total",
-			Help:        "Number of dns queries",
-			ConstLabels: constLabels,
-		}, []string{DNSBlastRootDomainLabel, DNSBlastSeedDomainLabel, DNSBlastProtocolLabel, StatusLabel})
-	httpCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name:        "db1000n_http_request_total",
-			Help:        "Number of http queries",
-			ConstLabels: constLabels,
-		}, []string{HTTPDestinationHostLabel, HTTPMethodLabel, StatusLabel})
-	packetgenCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name:        "db1000n_packetgen_total",
-			Help:        "Number of packet generation transfers",
+	for _, d := range counterDefinitions() {
+		*d.counter = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:        d.name,
+			Help:        d.help,
 			ConstLabels: constLabels,
-		}, []string{PacketgenHostLabel, PacketgenDstHostPortLabel, PacketgenProtocolLabel, StatusLabel})
-	slowlorisCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name:        "db1000n_slowloris_total",
-			Help:        "Number of sent raw tcp/udp packets",
-			ConstLabels: constLabels,
-		}, []string{SlowlorisAddressLabel, SlowlorisProtocolLabel, StatusLabel})
-	rawnetCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name:        "db1000n_rawnet_total",
-			Help:        "Number of sent raw tcp/udp packets",
-			ConstLabels: constLabels,
-		}, []string{RawnetAddressLabel, RawnetProtocolLabel, StatusLabel})
-	clientCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
-		Name:        "db1000n_client_total",
-		Help:        "Number of clients",
-		ConstLabels: constLabels,
-	}, []string{})
+		}, d.labels)
+	}
 }
 
 func registerMetrics() {
-	prometheus.MustRegister(dnsBlastCounter)
-	prometheus.MustRegister(httpCounter)
-	prometheus.MustRegister(packetgenCounter)
-	prometheus.MustRegister(slowlorisCounter)
-	prometheus.MustRegister(rawnetCounter)
-	prometheus.MustRegister(clientCounter)
+	for _, d := range counterDefinitions() {
+		prometheus.MustRegister(*d.counter)
+	}
+}
+
+// MetricSchema describes a single exported metric
+type MetricSchema struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Help        string   `json:"help"`
+	Labels      []string `json:"labels"`
+	ConstLabels []string `json:"const_labels"`
+}
+
+// Schema lists all metrics that can be exported to prometheus
+func Schema() []MetricSchema {
+	definitions := counterDefinitions()
+	res := make([]MetricSchema, 0, len(definitions))
+
+	for _, d := range definitions {
+		res = append(res, MetricSchema{Name: d.name, Type: "counter", Help: d.help, Labels: d.labels, ConstLabels: []string{CountryLabel}})
+	}
+
+	return res
 }
 
 // ExportPrometheusMetrics starts http server and export metrics at address <ip>:9090/metrics, also pushes metrics
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"io"
 	"text/tabwriter"
+	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/Arriven/db1000n/src/utils"
 )
 
 // Reporter gathers metrics across jobs and reports them.
@@ -21,15 +24,24 @@ type Reporter interface {
 type ZapReporter struct {
 	logger       *zap.Logger
 	groupTargets bool
+	delta        bool
 }
 
 // NewZapReporter creates a new Reporter using a zap logger.
-func NewZapReporter(logger *zap.Logger, groupTargets bool) Reporter {
-	return &ZapReporter{logger: logger, groupTargets: groupTargets}
+// When delta is set only the increments since the previous report are logged.
+func NewZapReporter(logger *zap.Logger, groupTargets, delta bool) Reporter {
+	return &ZapReporter{logger: logger, groupTargets: groupTargets, delta: delta}
 }
 
 func (r *ZapReporter) WriteSummary(tracker *StatsTracker) {
-	stats, totals, statsInterval, totalsInterval := tracker.sumStats(r.groupTargets)
+	stats, totals, statsInterval, totalsInterval, interval := tracker.sumStats(r.groupTargets)
+
+	if r.delta {
+		r.logger.Info("stats", zap.Object("total", &totalsInterval), zap.Object("targets", statsInterval),
+			zap.Float64("interval_seconds", interval.Seconds()))
+
+		return
+	}
 
 	r.logger.Info("stats", zap.Object("total", &totals), zap.Object("targets", stats),
 		zap.Object("total_since_last_report", &totalsInterval), zap.Object("targets_since_last_report", statsInterval))
@@ -40,11 +52,13 @@ func (r *ZapReporter) WriteSummary(tracker *StatsTracker) {
 type ConsoleReporter struct {
 	target       *bufio.Writer
 	groupTargets bool
+	delta        bool
 }
 
-// NewConsoleReporter creates a new Reporter which outputs straight to the console
-func NewConsoleReporter(target io.Writer, groupTargets bool) Reporter {
-	return &ConsoleReporter{target: bufio.NewWriter(target), groupTargets: groupTargets}
+// NewConsoleReporter creates a new Reporter which outputs straight to the console.
+// When delta is set only the increments since the previous report are printed.
+func NewConsoleReporter(target io.Writer, groupTargets, delta bool) Reporter {
+	return &ConsoleReporter{target: bufio.NewWriter(target), groupTargets: groupTargets, delta: delta}
 }
 
 func (r *ConsoleReporter) WriteSummary(tracker *StatsTracker) {
@@ -57,16 +71,22 @@ func (r *ConsoleReporter) WriteSummary(tracker *StatsTracker) {
 }
 
 func (r *ConsoleReporter) writeSummaryTo(tracker *StatsTracker, writer *tabwriter.Writer) {
-	stats, totals, statsInterval, totalsInterval := tracker.sumStats(r.groupTargets)
+	stats, totals, statsInterval, totalsInterval, interval := tracker.sumStats(r.groupTargets)
 
 	defer writer.Flush()
 
+	if r.delta {
+		writeDeltaSummaryTo(writer, statsInterval, totalsInterval, interval)
+
+		return
+	}
+
 	// Print table's header
 	fmt.Fprintln(writer, "\n --- Traffic stats ---")
 	fmt.Fprintf(writer, "|\tTarget\t|\tRequests attempted\t|\tRequests sent\t|\tResponses received\t|\tData sent\t|\tData received \t|\n")
 
 	// Print all table rows
-	for _, tgt := range stats.sortedTargets() {
+	for _, tgt := range utils.Keys(stats) {
 		printStatsRow(writer, tgt, stats[tgt], statsInterval[tgt])
 	}
 
@@ -76,6 +96,28 @@ func (r *ConsoleReporter) writeSummaryTo(tracker *StatsTracker, writer *tabwrite
 	fmt.Fprintln(writer)
 }
 
+func writeDeltaSummaryTo(writer *tabwriter.Writer, statsInterval PerTargetStats, totalsInterval Stats, interval time.Duration) {
+	fmt.Fprintf(writer, "\n --- Traffic stats for the last %.0f seconds ---\n", interval.Seconds())
+	fmt.Fprintf(writer, "|\tTarget\t|\tRequests attempted\t|\tRequests sent\t|\tResponses received\t|\tData sent\t|\tData received \t|\n")
+
+	for _, tgt := range utils.Keys(statsInterval) {
+		printDeltaStatsRow(writer, tgt, statsInterval[tgt])
+	}
+
+	fmt.Fprintln(writer, "|\t---\t|\t---\t|\t---\t|\t---\t|\t---\t|\t--- \t|")
+	printDeltaStatsRow(writer, "Total", totalsInterval)
+	fmt.Fprintln(writer)
+}
+
+func printDeltaStatsRow(writer *tabwriter.Writer, rowName string, diff Stats) {
+	const BytesInMegabyte = 1024 * 1024
+
+	fmt.Fprintf(writer, "|\t%s\t|\t%d\t|\t%d\t|\t%d\t|\t%.2f MB\t|\t%.2f MB \t|\n", rowName,
+		diff[RequestsAttemptedStat], diff[RequestsSentStat], diff[ResponsesReceivedStat],
+		float64(diff[BytesSentStat])/BytesInMegabyte, float64(diff[BytesReceivedStat])/BytesInMegabyte,
+	)
+}
+
 func printStatsRow(writer *tabwriter.Writer, rowName string, stats Stats, diff Stats) {
 	const BytesInMegabyte = 1024 * 1024
 
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestZapReporterDelta(t *testing.T) {
+	t.Parallel()
+
+	core, logs := observer.New(zap.InfoLevel)
+	reporter := NewZapReporter(zap.New(core), false, true)
+
+	var data Metrics
+
+	tracker := NewStatsTracker(&data)
+	acc := data.NewAccumulator("job")
+
+	for i, requests := range []uint64{5, 0, 7} {
+		acc.Add("tcp://localhost", RequestsAttemptedStat, requests).Add("tcp://localhost", BytesSentStat, requests*100).Flush()
+		reporter.WriteSummary(tracker)
+
+		entries := logs.TakeAll()
+		if len(entries) != 1 {
+			t.Fatalf("cycle %d: expected 1 log entry, got %d", i, len(entries))
+		}
+
+		fields := entries[0].ContextMap()
+
+		total, ok := fields["total"].(map[string]any)
+		if !ok {
+			t.Fatalf("cycle %d: missing total in %v", i, fields)
+		}
+
+		if total["requests_attempted"] != requests || total["bytes_sent"] != requests*100 {
+			t.Errorf("cycle %d: expected %d requests and %d bytes, got %v", i, requests, requests*100, total)
+		}
+
+		if interval, ok := fields["interval_seconds"].(float64); !ok || interval < 0 {
+			t.Errorf("cycle %d: unexpected interval_seconds %v", i, fields["interval_seconds"])
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package metrics
 
 import (
-	"sort"
+	"encoding/json"
 
 	"go.uber.org/zap/zapcore"
+
+	"github.com/Arriven/db1000n/src/utils"
 )
 
 type (
@@ -21,21 +23,11 @@ const (
 	ResponsesReceivedStat
 	BytesSentStat
 	BytesReceivedStat
+	DedupSkippedStat
 
 	NumStats
 )
 
-func (ts PerTargetStats) sortedTargets() []string {
-	res := make([]string, 0, len(ts))
-	for k := range ts {
-		res = append(res, k)
-	}
-
-	sort.Strings(res)
-
-	return res
-}
-
 func Diff(lhs, rhs Stats) Stats {
 	var res Stats
 	for i := range res {
@@ -60,7 +52,7 @@ func (ts PerTargetStats) Diff(other PerTargetStats) PerTargetStats {
 
 // MarshalLogObject is required to log PerTargetStats objects to zap
 func (ts PerTargetStats) MarshalLogObject(enc zapcore.ObjectEncoder) error {
-	for _, tgt := range ts.sortedTargets() {
+	for _, tgt := range utils.Keys(ts) {
 		tgtStats := ts[tgt]
 
 		if err := enc.AddObject(tgt, &tgtStats); err != nil {
@@ -71,13 +63,44 @@ func (ts PerTargetStats) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 	return nil
 }
 
+var statNames = [NumStats]string{
+	RequestsAttemptedStat: "requests_attempted",
+	RequestsSentStat:      "requests_sent",
+	ResponsesReceivedStat: "responses_received",
+	BytesSentStat:         "bytes_sent",
+	BytesReceivedStat:     "bytes_received",
+	DedupSkippedStat:      "dedup_skip",
+}
+
 // MarshalLogObject is required to log Stats objects to zap
 func (stats *Stats) MarshalLogObject(enc zapcore.ObjectEncoder) error {
-	enc.AddUint64("requests_attempted", stats[RequestsAttemptedStat])
-	enc.AddUint64("requests_sent", stats[RequestsSentStat])
-	enc.AddUint64("responses_received", stats[ResponsesReceivedStat])
-	enc.AddUint64("bytes_sent", stats[BytesSentStat])
-	enc.AddUint64("bytes_received", stats[BytesReceivedStat])
+	for s := RequestsAttemptedStat; s < NumStats; s++ {
+		enc.AddUint64(statNames[s], stats[s])
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes Stats as an object keyed by stat names
+func (stats Stats) MarshalJSON() ([]byte, error) {
+	res := make(map[string]uint64, NumStats)
+	for s := RequestsAttemptedStat; s < NumStats; s++ {
+		res[statNames[s]] = stats[s]
+	}
+
+	return json.Marshal(res)
+}
+
+// UnmarshalJSON is the inverse of MarshalJSON, unknown stat names are ignored
+func (stats *Stats) UnmarshalJSON(data []byte) error {
+	var raw map[string]uint64
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	for s := RequestsAttemptedStat; s < NumStats; s++ {
+		stats[s] = raw[statNames[s]]
+	}
 
 	return nil
 }
@@ -1,20 +1,35 @@
 package metrics
 
+import "time"
+
 func NewStatsTracker(metrics *Metrics) *StatsTracker {
-	return &StatsTracker{metrics: metrics}
+	return &StatsTracker{metrics: metrics, lastReport: time.Now()}
 }
 
 // StatsTracker generalizes tracking stats changes between reports
 type StatsTracker struct {
 	lastStats  PerTargetStats
 	lastTotals Stats
+	lastReport time.Time
 	metrics    *Metrics
 }
 
-func (st *StatsTracker) sumStats(groupTargets bool) (stats PerTargetStats, totals Stats, statsInterval PerTargetStats, totalsInterval Stats) {
+func (st *StatsTracker) sumStats(groupTargets bool) (
+	stats PerTargetStats, totals Stats, statsInterval PerTargetStats, totalsInterval Stats, interval time.Duration,
+) {
+	now := time.Now()
 	stats, totals = st.metrics.SumAllStats(groupTargets)
-	statsInterval, totalsInterval = stats.Diff(st.lastStats), Diff(totals, st.lastTotals)
-	st.lastStats, st.lastTotals = stats, totals
+	statsInterval, totalsInterval, interval = stats.Diff(st.lastStats), Diff(totals, st.lastTotals), now.Sub(st.lastReport)
+	st.lastStats, st.lastTotals, st.lastReport = stats, totals, now
 
 	return
 }
+
+// Snapshot returns current per-target and total stats without affecting the next report's interval stats
+func (st *StatsTracker) Snapshot(groupTargets bool) (stats PerTargetStats, totals Stats) {
+	if st == nil {
+		return nil, totals
+	}
+
+	return st.metrics.SumAllStats(groupTargets)
+}
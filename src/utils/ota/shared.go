@@ -1,18 +1,16 @@
 package ota
 
+import "github.com/Arriven/db1000n/src/utils"
+
 func appendArgIfNotPresent(osArgs, extraArgs []string) []string {
 	osArgsMap := make(map[string]any, len(osArgs))
 	for _, osArg := range osArgs {
 		osArgsMap[osArg] = nil
 	}
 
-	acceptedExtraArgs := make([]string, 0)
-
-	for _, extraArg := range extraArgs {
-		if _, isAlreadyOSArg := osArgsMap[extraArg]; !isAlreadyOSArg {
-			acceptedExtraArgs = append(acceptedExtraArgs, extraArg)
-		}
-	}
+	return append(osArgs, utils.Filter(extraArgs, func(extraArg string) bool {
+		_, isAlreadyOSArg := osArgsMap[extraArg]
 
-	return append(osArgs, acceptedExtraArgs...)
+		return !isAlreadyOSArg
+	})...)
 }
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"runtime"
+	"time"
+)
+
+// ResourceUsage tracks memory and cpu consumption of the current process
+type ResourceUsage struct {
+	lastCPUTime time.Duration
+	lastCheck   time.Time
+}
+
+// MemoryMB returns the amount of memory obtained from the OS by the go runtime
+func (u *ResourceUsage) MemoryMB() float64 {
+	const bytesInMegabyte = 1024 * 1024
+
+	var stats runtime.MemStats
+
+	runtime.ReadMemStats(&stats)
+
+	return float64(stats.Sys) / bytesInMegabyte
+}
+
+// CPUPct returns average cpu usage as a percentage of all available cores since the previous call
+// or since the process start for the first call
+func (u *ResourceUsage) CPUPct() float64 {
+	const hundred = 100
+
+	now, cpuTime := time.Now(), processCPUTime()
+	if u.lastCheck.IsZero() {
+		u.lastCheck = processStart
+	}
+
+	elapsed := now.Sub(u.lastCheck)
+	used := cpuTime - u.lastCPUTime
+	u.lastCheck, u.lastCPUTime = now, cpuTime
+
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return hundred * float64(used) / float64(elapsed) / float64(runtime.NumCPU())
+}
+
+var processStart = time.Now()
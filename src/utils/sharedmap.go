@@ -0,0 +1,47 @@
+package utils
+
+import "sort"
+
+// SharedMap is a string-keyed map that is safe for concurrent use by multiple jobs
+type SharedMap struct {
+	values SyncMap[string, any] // Zero value is empty and ready for use
+}
+
+var sharedMaps SyncMap[string, *SharedMap] // name -> *SharedMap
+
+// GetSharedMap returns a global SharedMap with the given name creating it if necessary
+func GetSharedMap(name string) *SharedMap {
+	m, _ := sharedMaps.LoadOrStore(name, &SharedMap{})
+
+	return m
+}
+
+func (m *SharedMap) Store(key string, value any) { m.values.Store(key, value) }
+
+func (m *SharedMap) Load(key string) (value any, ok bool) { return m.values.Load(key) }
+
+func (m *SharedMap) Delete(key string) { m.values.Delete(key) }
+
+// Keys returns sorted list of keys currently present in the map
+func (m *SharedMap) Keys() []string {
+	var keys []string
+
+	m.values.Range(func(key string, _ any) bool {
+		keys = append(keys, key)
+
+		return true
+	})
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Clear removes all keys from the map
+func (m *SharedMap) Clear() {
+	m.values.Range(func(key string, _ any) bool {
+		m.values.Delete(key)
+
+		return true
+	})
+}
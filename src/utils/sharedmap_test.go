@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSharedMapContention(t *testing.T) {
+	t.Parallel()
+
+	const (
+		goroutines = 64
+		keys       = 100
+	)
+
+	var wg sync.WaitGroup
+
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+
+		go func(g int) {
+			defer wg.Done()
+
+			m := GetSharedMap("contention")
+			for k := 0; k < keys; k++ {
+				m.Store(strconv.Itoa(k), g)
+				m.Load(strconv.Itoa(k))
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	m := GetSharedMap("contention")
+	if got := len(m.Keys()); got != keys {
+		t.Fatalf("expected %d keys, got %d", keys, got)
+	}
+
+	m.Delete("0")
+
+	if _, ok := m.Load("0"); ok {
+		t.Error("key is present after delete")
+	}
+
+	m.Clear()
+
+	if got := len(m.Keys()); got != 0 {
+		t.Errorf("expected empty map after clear, got %d keys", got)
+	}
+}
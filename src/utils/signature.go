@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// ParseEd25519PrivateKey parses a PKCS #8 PEM encoded ed25519 private key
+func ParseEd25519PrivateKey(pemKey string) (ed25519.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("expected an ed25519 private key, got %T", key)
+	}
+
+	return privateKey, nil
+}
+
+func signedRequestMessage(method, url string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+
+	return []byte(method + url + hex.EncodeToString(bodyHash[:]))
+}
+
+// Ed25519Sign returns a base64 encoded signature of method+url+hex(sha256(body))
+func Ed25519Sign(key ed25519.PrivateKey, method, url string, body []byte) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, signedRequestMessage(method, url, body)))
+}
+
+// Ed25519Verify checks a signature produced by Ed25519Sign
+func Ed25519Verify(key ed25519.PublicKey, method, url string, body []byte, signature string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	return ed25519.Verify(key, signedRequestMessage(method, url, body), decoded)
+}
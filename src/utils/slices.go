@@ -0,0 +1,62 @@
+package utils
+
+// Batch splits items into consecutive batches of at most size elements, non-positive size puts everything into a single batch
+func Batch[T any](items []T, size int) [][]T {
+	if len(items) == 0 {
+		return nil
+	}
+
+	if size <= 0 {
+		size = len(items)
+	}
+
+	result := make([][]T, 0, (len(items)+size-1)/size)
+
+	for start := 0; start < len(items); start += size {
+		end := start + size
+		if end > len(items) {
+			end = len(items)
+		}
+
+		// capping capacity keeps appends to one batch from overwriting the next one
+		result = append(result, items[start:end:end])
+	}
+
+	return result
+}
+
+// Chunk splits items into exactly n consecutive sub-slices whose sizes differ by at most one, earlier chunks get the extra elements.
+// Returns nil for non-positive n
+func Chunk[T any](items []T, n int) [][]T {
+	if n <= 0 {
+		return nil
+	}
+
+	result := make([][]T, 0, n)
+	size, extra := len(items)/n, len(items)%n
+
+	for start, i := 0, 0; i < n; i++ {
+		end := start + size
+		if i < extra {
+			end++
+		}
+
+		result = append(result, items[start:end:end])
+		start = end
+	}
+
+	return result
+}
+
+// Filter returns items for which fn returns true, preserving their order
+func Filter[T any](items []T, fn func(T) bool) []T {
+	var result []T
+
+	for _, item := range items {
+		if fn(item) {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
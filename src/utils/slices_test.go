@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		items    []int
+		size     int
+		expected [][]int
+	}{
+		{items: []int{1, 2, 3, 4, 5}, size: 2, expected: [][]int{{1, 2}, {3, 4}, {5}}},
+		{items: []int{1, 2}, size: 5, expected: [][]int{{1, 2}}},
+		{items: []int{1, 2}, size: 0, expected: [][]int{{1, 2}}},
+		{items: nil, size: 2, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		if got := Batch(tc.items, tc.size); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("Batch(%v, %d) = %v, expected %v", tc.items, tc.size, got, tc.expected)
+		}
+	}
+}
+
+func TestChunk(t *testing.T) {
+	t.Parallel()
+
+	if got, expected := Chunk([]int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, 4), [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8}, {9, 10}}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected chunks %v, expected %v", got, expected)
+	}
+
+	if got := Chunk([]int{1}, 3); len(got) != 3 || len(got[0]) != 1 || len(got[1]) != 0 || len(got[2]) != 0 {
+		t.Errorf("expected exactly 3 chunks with trailing empty ones, got %v", got)
+	}
+}
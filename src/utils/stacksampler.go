@@ -0,0 +1,190 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StackCount is a unique goroutine stack and the amount of goroutines sharing it
+type StackCount struct {
+	Stack []string `json:"stack"`
+	Count int      `json:"count"`
+}
+
+// StackSnapshot summarizes goroutines at a point in time
+type StackSnapshot struct {
+	Timestamp  time.Time      `json:"timestamp"`
+	Goroutines int            `json:"goroutines"`
+	ByFunction map[string]int `json:"by_function"` // keyed by the topmost function of each goroutine
+	TopStacks  []StackCount   `json:"top_stacks"`
+}
+
+// StackSamplerConfig configures SampleStacks
+type StackSamplerConfig struct {
+	Interval time.Duration
+	Path     string
+	MaxBytes int64 // the file is rotated to Path.1 once it grows beyond this size
+}
+
+// SampleStacks periodically appends a StackSnapshot of all goroutines to the configured file until the context is cancelled.
+// It's a lightweight alternative to pprof that doesn't require an http endpoint
+func SampleStacks(ctx context.Context, cfg StackSamplerConfig) error {
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		if err := appendSnapshot(cfg, TakeStackSnapshot()); err != nil {
+			return err
+		}
+	}
+}
+
+func appendSnapshot(cfg StackSamplerConfig, snapshot StackSnapshot) error {
+	const perm = 0o600
+
+	if info, err := os.Stat(cfg.Path); err == nil && cfg.MaxBytes > 0 && info.Size() > cfg.MaxBytes {
+		if err := os.Rename(cfg.Path, cfg.Path+".1"); err != nil {
+			return err
+		}
+	}
+
+	line, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+
+	return err
+}
+
+// TakeStackSnapshot captures stacks of all goroutines and aggregates them
+func TakeStackSnapshot() StackSnapshot {
+	const topStacks = 10
+
+	snapshot := StackSnapshot{Timestamp: time.Now(), ByFunction: make(map[string]int)}
+	stackCounts := make(map[string]*StackCount)
+
+	for _, goroutine := range bytes.Split(allStacks(), []byte("\n\n")) {
+		stack := parseStack(string(goroutine))
+		if len(stack) == 0 {
+			continue
+		}
+
+		snapshot.Goroutines++
+		snapshot.ByFunction[stack[0]]++
+
+		key := strings.Join(stack, "\n")
+		if count, ok := stackCounts[key]; ok {
+			count.Count++
+		} else {
+			stackCounts[key] = &StackCount{Stack: stack, Count: 1}
+		}
+	}
+
+	for _, count := range stackCounts {
+		snapshot.TopStacks = append(snapshot.TopStacks, *count)
+	}
+
+	sort.Slice(snapshot.TopStacks, func(i, j int) bool { return snapshot.TopStacks[i].Count > snapshot.TopStacks[j].Count })
+
+	if len(snapshot.TopStacks) > topStacks {
+		snapshot.TopStacks = snapshot.TopStacks[:topStacks]
+	}
+
+	return snapshot
+}
+
+func allStacks() []byte {
+	const initialSize = 1 << 16
+
+	buf := make([]byte, initialSize)
+
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// parseStack extracts function names from a single goroutine dump:
+//
+//	goroutine 1 [running]:
+//	main.main()
+//		/path/main.go:10 +0x1d
+func parseStack(goroutine string) []string {
+	lines := strings.Split(strings.TrimSpace(goroutine), "\n")
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "goroutine ") {
+		return nil
+	}
+
+	var functions []string
+
+	for _, line := range lines[1:] {
+		if strings.HasPrefix(line, "\t") {
+			continue // file and line of the previous function
+		}
+
+		if strings.HasPrefix(line, "created by ") {
+			creator, _, _ := strings.Cut(strings.TrimPrefix(line, "created by "), " in goroutine ")
+			functions = append(functions, creator)
+
+			continue
+		}
+
+		// strip call arguments
+		if i := strings.LastIndex(line, "("); i > 0 && strings.HasSuffix(line, ")") {
+			line = line[:i]
+		}
+
+		functions = append(functions, line)
+	}
+
+	return functions
+}
+
+// WatchStackUsage checks memory used by goroutine stacks every interval and calls onExceeded once it's over maxBytes.
+// Goroutine stacks live in the go heap rather than the thread stack so the runtime stats are used instead of VmStack.
+func WatchStackUsage(ctx context.Context, maxBytes uint64, interval time.Duration, onExceeded func(inuse uint64)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var stats runtime.MemStats
+
+		runtime.ReadMemStats(&stats)
+
+		if stats.StackInuse > maxBytes {
+			onExceeded(stats.StackInuse)
+
+			return
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+//go:noinline
+func recurseAndWait(depth int, release <-chan struct{}) byte {
+	var frame [1024]byte
+
+	if depth == 0 {
+		<-release
+
+		return frame[0]
+	}
+
+	frame[depth%len(frame)] = byte(depth)
+
+	return recurseAndWait(depth-1, release) + frame[depth%len(frame)]
+}
+
+func TestWatchStackUsageDetectsDeepRecursion(t *testing.T) {
+	t.Parallel()
+
+	const (
+		maxBytes = 8 << 20
+		depth    = 32 << 10 // ~32MB of stack with 1KB frames
+	)
+
+	release := make(chan struct{})
+	defer close(release)
+
+	go recurseAndWait(depth, release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	exceeded := make(chan uint64, 1)
+
+	WatchStackUsage(ctx, maxBytes, 10*time.Millisecond, func(inuse uint64) { exceeded <- inuse })
+
+	select {
+	case inuse := <-exceeded:
+		if inuse <= maxBytes {
+			t.Errorf("reported stack usage %d is below the limit", inuse)
+		}
+	default:
+		t.Fatal("stack usage limit wasn't detected")
+	}
+}
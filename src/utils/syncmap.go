@@ -0,0 +1,36 @@
+package utils
+
+import "sync"
+
+// SyncMap is a typed wrapper around sync.Map. Zero value is empty and ready for use
+type SyncMap[K comparable, V any] struct {
+	m sync.Map
+}
+
+func (m *SyncMap[K, V]) Load(key K) (value V, ok bool) {
+	v, ok := m.m.Load(key)
+	if !ok {
+		return value, false
+	}
+
+	return v.(V), true //nolint:forcetypeassert // only values of type V are ever stored
+}
+
+func (m *SyncMap[K, V]) Store(key K, value V) { m.m.Store(key, value) }
+
+// LoadOrStore returns the existing value for the key if present, otherwise it stores and returns the given value.
+// The loaded result is true if the value was loaded, false if stored.
+func (m *SyncMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	v, loaded := m.m.LoadOrStore(key, value)
+
+	return v.(V), loaded //nolint:forcetypeassert // only values of type V are ever stored
+}
+
+func (m *SyncMap[K, V]) Delete(key K) { m.m.Delete(key) }
+
+// Range calls f sequentially for each key and value present in the map. If f returns false, range stops the iteration.
+func (m *SyncMap[K, V]) Range(f func(key K, value V) bool) {
+	m.m.Range(func(k, v any) bool {
+		return f(k.(K), v.(V)) //nolint:forcetypeassert // only values of type K and V are ever stored
+	})
+}
@@ -0,0 +1,104 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// values larger than this are left out of context snapshots to keep records readable
+const maxSnapshotValueSize = 1024
+
+type renderRecord struct {
+	TemplateSource  string `json:"template_source"`
+	ContextSnapshot any    `json:"context_snapshot,omitempty"`
+	RenderedOutput  string `json:"rendered_output"`
+	DurationNS      int64  `json:"duration_ns"`
+}
+
+// snapshotKeys are the context values known to be available to job templates, contexts can't be enumerated
+// so only these make it into snapshots. Secrets like oauth tokens are deliberately left out.
+var snapshotKeys = []string{"goos", "goarch", "version", "global", "config", "state", "main_error"}
+
+var (
+	debugFile  atomic.Value // *os.File, nil unless recording is enabled
+	debugMutex sync.Mutex   // serializes writes so that records don't interleave
+)
+
+// EnableDebugRecording makes ParseAndExecute append a json record of every render to the file at path
+func EnableDebugRecording(path string) error {
+	const perm = 0o600
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	debugFile.Store(file)
+
+	return nil
+}
+
+func debugOutput() *os.File {
+	file, _ := debugFile.Load().(*os.File)
+
+	return file
+}
+
+func recordRender(file *os.File, input string, data any, output string, duration time.Duration) {
+	line, err := json.Marshal(renderRecord{
+		TemplateSource:  input,
+		ContextSnapshot: snapshot(data),
+		RenderedOutput:  output,
+		DurationNS:      duration.Nanoseconds(),
+	})
+	if err != nil {
+		return
+	}
+
+	debugMutex.Lock()
+	defer debugMutex.Unlock()
+
+	// single write per record so that a crash can't leave half of a record in the file
+	_, _ = file.Write(append(line, '\n'))
+}
+
+func snapshot(data any) any {
+	switch data := data.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(data))
+
+		for k, v := range data {
+			addSnapshotValue(result, k, v)
+		}
+
+		return result
+	case context.Context:
+		result := make(map[string]any, len(snapshotKeys))
+
+		for _, k := range snapshotKeys {
+			if v := data.Value(ContextKey(k)); v != nil {
+				addSnapshotValue(result, k, v)
+			}
+		}
+
+		return result
+	}
+
+	if s := fmt.Sprint(data); len(s) <= maxSnapshotValueSize {
+		return s
+	}
+
+	return nil
+}
+
+// addSnapshotValue adds the value unless it can't be encoded or is too large
+func addSnapshotValue(snapshot map[string]any, key string, value any) {
+	if encoded, err := json.Marshal(value); err == nil && len(encoded) <= maxSnapshotValueSize {
+		snapshot[key] = json.RawMessage(encoded)
+	}
+}
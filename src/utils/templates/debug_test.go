@@ -0,0 +1,76 @@
+package templates
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDebugRecordingJobContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.jsonl")
+	if err := EnableDebugRecording(path); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		if file := debugOutput(); file != nil {
+			file.Close()
+		}
+
+		debugFile.Store((*os.File)(nil))
+	})
+
+	// same values the runner puts into job contexts, the config is too large to be recorded
+	ctx := context.WithValue(context.Background(), ContextKey("goos"), runtime.GOOS)
+	ctx = context.WithValue(ctx, ContextKey("version"), "0.0.1")
+	ctx = context.WithValue(ctx, ContextKey("config"), map[string]any{"args": strings.Repeat("x", 2*maxSnapshotValueSize)})
+	ctx = context.WithValue(ctx, ContextKey("oauth_token"), "secret")
+
+	const input = `{{ .Value (ctx_key "goos") }}`
+
+	if output := ParseAndExecute(zap.NewNop(), input, ctx); output != runtime.GOOS {
+		t.Fatalf("unexpected output %q", output)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		t.Fatal("no render was recorded")
+	}
+
+	var record struct {
+		TemplateSource  string         `json:"template_source"`
+		ContextSnapshot map[string]any `json:"context_snapshot"`
+		RenderedOutput  string         `json:"rendered_output"`
+	}
+
+	if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.TemplateSource != input || record.RenderedOutput != runtime.GOOS {
+		t.Errorf("unexpected record %+v", record)
+	}
+
+	if record.ContextSnapshot["goos"] != runtime.GOOS || record.ContextSnapshot["version"] != "0.0.1" {
+		t.Errorf("expected small values in the snapshot, got %v", record.ContextSnapshot)
+	}
+
+	for _, key := range []string{"config", "oauth_token"} {
+		if _, ok := record.ContextSnapshot[key]; ok {
+			t.Errorf("%s shouldn't be in the snapshot", key)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package templates
+
+import (
+	"github.com/Arriven/db1000n/src/utils"
+)
+
+// sharedMapSet returns an empty string so that it can be used inside templates without affecting the output
+func sharedMapSet(name, key string, value any) string {
+	utils.GetSharedMap(name).Store(key, value)
+
+	return ""
+}
+
+func sharedMapGet(name, key string) any {
+	value, _ := utils.GetSharedMap(name).Load(key)
+
+	return value
+}
+
+func sharedMapDelete(name, key string) string {
+	utils.GetSharedMap(name).Delete(key)
+
+	return ""
+}
+
+func sharedMapKeys(name string) []string {
+	return utils.GetSharedMap(name).Keys()
+}
+
+func sharedMapClear(name string) string {
+	utils.GetSharedMap(name).Clear()
+
+	return ""
+}
@@ -148,6 +148,11 @@ func Parse(input string) (*template.Template, error) {
 		"usub64":              usub64,
 		"ctx_key":             ctxKey,
 		"cookie_string":       cookieString,
+		"shared_map_set":      sharedMapSet,
+		"shared_map_get":      sharedMapGet,
+		"shared_map_delete":   sharedMapDelete,
+		"shared_map_keys":     sharedMapKeys,
+		"shared_map_clear":    sharedMapClear,
 	}).Parse(input)
 }
 
@@ -165,6 +170,19 @@ func Execute(logger *zap.Logger, tpl *template.Template, data any) string {
 
 // ParseAndExecute template, returns input string in case of errors. Expensive operation.
 func ParseAndExecute(logger *zap.Logger, input string, data any) string {
+	file := debugOutput()
+	if file == nil {
+		return parseAndExecute(logger, input, data)
+	}
+
+	start := time.Now()
+	output := parseAndExecute(logger, input, data)
+	recordRender(file, input, data, output, time.Since(start))
+
+	return output
+}
+
+func parseAndExecute(logger *zap.Logger, input string, data any) string {
 	tpl, err := Parse(input)
 	if err != nil {
 		logger.Debug("error parsing template", zap.Error(err))
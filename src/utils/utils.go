@@ -101,7 +101,13 @@ func Max[T int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 |
 	return b
 }
 
-func NonNilOrDefault[T any](v *T, dflt T) T {
+// Ptr returns a pointer to a copy of v, useful for optional config fields
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Deref returns the value v points to or dflt if v is nil
+func Deref[T any](v *T, dflt T) T {
 	if v != nil {
 		return *v
 	}
@@ -176,3 +182,12 @@ func Unmarshal(input []byte, output any, format string) error {
 
 	return nil
 }
+
+// Must returns v or panics if err is not nil, meant for startup code where errors are unrecoverable
+func Must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
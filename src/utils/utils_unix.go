@@ -6,6 +6,7 @@ package utils
 import (
 	"net"
 	"syscall"
+	"time"
 
 	sys "golang.org/x/sys/unix"
 )
@@ -23,6 +24,16 @@ func UpdateRLimit() error {
 	return sys.Setrlimit(sys.RLIMIT_NOFILE, &rLimit)
 }
 
+// processCPUTime returns user and system cpu time consumed by the current process
+func processCPUTime() time.Duration {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0
+	}
+
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano())
+}
+
 func getSockaddrByName(name string) syscall.Sockaddr {
 	ief, err := net.InterfaceByName(name)
 	if err != nil {
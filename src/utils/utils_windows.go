@@ -1,11 +1,19 @@
 package utils
 
-import "syscall"
+import (
+	"syscall"
+	"time"
+)
 
 func UpdateRLimit() error {
 	return nil
 }
 
+// cpu usage tracking is not supported on windows
+func processCPUTime() time.Duration {
+	return 0
+}
+
 func BindToInterface(name string) func(network, address string, conn syscall.RawConn) error {
 	return func(network, address string, conn syscall.RawConn) error {
 		return nil
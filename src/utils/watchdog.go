@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrActivityTimeout is reported by the context of a Watchdog that didn't get a heartbeat in time
+var ErrActivityTimeout = errors.New("no activity within the activity timeout")
+
+// Watchdog cancels its context if Heartbeat isn't called for longer than the timeout.
+// Nil Watchdog is valid and never fires.
+type Watchdog struct {
+	lastHeartbeat int64 // unix nanoseconds
+	fired         int32
+}
+
+type watchdogContext struct {
+	context.Context
+	watchdog *Watchdog
+}
+
+func (c watchdogContext) Err() error {
+	if c.watchdog.Fired() {
+		return ErrActivityTimeout
+	}
+
+	return c.Context.Err()
+}
+
+// NewWatchdog returns a context that's cancelled with ErrActivityTimeout once there was no heartbeat for timeout.
+// Non-positive timeout disables the watchdog and returns ctx as is with a nil Watchdog.
+func NewWatchdog(ctx context.Context, timeout time.Duration) (context.Context, *Watchdog) {
+	if timeout <= 0 {
+		return ctx, nil
+	}
+
+	w := &Watchdog{lastHeartbeat: time.Now().UnixNano()}
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+
+			idle := time.Since(time.Unix(0, atomic.LoadInt64(&w.lastHeartbeat)))
+			if idle >= timeout {
+				atomic.StoreInt32(&w.fired, 1)
+				cancel()
+
+				return
+			}
+
+			timer.Reset(timeout - idle)
+		}
+	}()
+
+	return watchdogContext{Context: ctx, watchdog: w}, w
+}
+
+// Heartbeat postpones the watchdog deadline
+func (w *Watchdog) Heartbeat() {
+	if w != nil {
+		atomic.StoreInt64(&w.lastHeartbeat, time.Now().UnixNano())
+	}
+}
+
+// Fired reports whether the watchdog has cancelled its context
+func (w *Watchdog) Fired() bool {
+	return w != nil && atomic.LoadInt32(&w.fired) == 1
+}
+
+// Err returns ErrActivityTimeout if the watchdog has fired and nil otherwise
+func (w *Watchdog) Err() error {
+	if w.Fired() {
+		return ErrActivityTimeout
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	t.Parallel()
+
+	const timeout = 200 * time.Millisecond
+
+	ctx, watchdog := NewWatchdog(context.Background(), timeout)
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(timeout / 4)
+		watchdog.Heartbeat()
+	}
+
+	if err := ctx.Err(); err != nil {
+		t.Fatalf("expected the context to be alive while heartbeats arrive, got %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(10 * timeout):
+		t.Fatal("expected the context to be cancelled without heartbeats")
+	}
+
+	if err := ctx.Err(); !errors.Is(err, ErrActivityTimeout) {
+		t.Errorf("expected ErrActivityTimeout, got %v", err)
+	}
+}